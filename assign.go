@@ -0,0 +1,136 @@
+package tracrpc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// assign stores value, which was decoded into interface{}, into reply.
+// The value is converted to the type of reply in the same manner as github.com/kolo/xmlrpc decodes.
+func assign(reply interface{}, value interface{}) error {
+	rv := reflect.ValueOf(reply)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("reply must be a non-nil pointer")
+	}
+
+	return assignValue(rv.Elem(), value)
+}
+
+// assignValue stores value into dst.
+func assignValue(dst reflect.Value, value interface{}) error {
+	if isNil(value) {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	if dst.Kind() == reflect.Ptr {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return assignValue(dst.Elem(), value)
+	}
+
+	src := reflect.ValueOf(value)
+	if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		switch src.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i = src.Int()
+		case reflect.Float32, reflect.Float64:
+			if f := src.Float(); f != math.Trunc(f) {
+				return mismatchError(dst, value)
+			}
+			i = int64(src.Float())
+		default:
+			return mismatchError(dst, value)
+		}
+		if dst.OverflowInt(i) {
+			return fmt.Errorf("value %v overflows %s", value, dst.Type())
+		}
+		dst.SetInt(i)
+	case reflect.Float32, reflect.Float64:
+		switch src.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			dst.SetFloat(float64(src.Int()))
+		case reflect.Float32, reflect.Float64:
+			dst.SetFloat(src.Float())
+		default:
+			return mismatchError(dst, value)
+		}
+	case reflect.String:
+		if src.Kind() != reflect.String {
+			return mismatchError(dst, value)
+		}
+		dst.SetString(src.String())
+	case reflect.Bool:
+		if src.Kind() != reflect.Bool {
+			return mismatchError(dst, value)
+		}
+		dst.SetBool(src.Bool())
+	case reflect.Slice:
+		if src.Kind() != reflect.Slice {
+			return mismatchError(dst, value)
+		}
+		slice := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			if err := assignValue(slice.Index(i), src.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		dst.Set(slice)
+	case reflect.Map:
+		if src.Kind() != reflect.Map || dst.Type().Key().Kind() != reflect.String {
+			return mismatchError(dst, value)
+		}
+		m := reflect.MakeMapWithSize(dst.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			if err := assignValue(elem, iter.Value().Interface()); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(iter.Key().String()).Convert(dst.Type().Key()), elem)
+		}
+		dst.Set(m)
+	case reflect.Struct:
+		members, ok := value.(map[string]interface{})
+		if !ok {
+			return mismatchError(dst, value)
+		}
+		for i := 0; i < dst.NumField(); i++ {
+			field := dst.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			name := strings.TrimSuffix(field.Tag.Get("xmlrpc"), ",omitempty")
+			if name == "" {
+				name = field.Name
+			}
+			member, ok := members[name]
+			if !ok {
+				continue
+			}
+			if err := assignValue(dst.Field(i), member); err != nil {
+				return err
+			}
+		}
+	default:
+		return mismatchError(dst, value)
+	}
+
+	return nil
+}
+
+// mismatchError returns the error for the value which cannot be stored into dst.
+func mismatchError(dst reflect.Value, value interface{}) error {
+	return fmt.Errorf("type mismatch: cannot assign %T to %s", value, dst.Type())
+}
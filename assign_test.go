@@ -0,0 +1,100 @@
+package tracrpc
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAssign(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		reply    interface{}
+		expected interface{}
+		wantErr  bool
+	}{
+		{
+			name:     "Int",
+			value:    int64(7),
+			reply:    new(int),
+			expected: 7,
+		},
+		{
+			name:     "IntFromFloat",
+			value:    float64(7),
+			reply:    new(int),
+			expected: 7,
+		},
+		{
+			name:    "IntFromFraction",
+			value:   7.5,
+			reply:   new(int),
+			wantErr: true,
+		},
+		{
+			name:     "Nil",
+			value:    nil,
+			reply:    new([]string),
+			expected: []string(nil),
+		},
+		{
+			name:     "Slice",
+			value:    []interface{}{"Biwako", "Kasumigaura"},
+			reply:    new([]string),
+			expected: []string{"Biwako", "Kasumigaura"},
+		},
+		{
+			name:     "Interface",
+			value:    []interface{}{int64(1), "a"},
+			reply:    new([]interface{}),
+			expected: []interface{}{int64(1), "a"},
+		},
+		{
+			name: "Struct",
+			value: map[string]interface{}{
+				"name":         "sakuradamon",
+				"lastModified": time.Date(1860, time.March, 24, 0, 0, 0, 0, time.UTC),
+				"version":      int64(1),
+				"unknown":      "ignored",
+			},
+			reply: new(PageInfo),
+			expected: PageInfo{
+				Name:         "sakuradamon",
+				LastModified: time.Date(1860, time.March, 24, 0, 0, 0, 0, time.UTC),
+				Version:      1,
+			},
+		},
+		{
+			name:     "Map",
+			value:    map[string]interface{}{"a": int64(1)},
+			reply:    new(map[string]int),
+			expected: map[string]int{"a": 1},
+		},
+		{
+			name:    "Mismatch",
+			value:   "string",
+			reply:   new(bool),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := assign(tt.reply, tt.value)
+			if err != nil {
+				if tt.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				t.Fatal("expected error, got nil")
+			}
+			res := reflect.ValueOf(tt.reply).Elem().Interface()
+			if !reflect.DeepEqual(res, tt.expected) {
+				t.Fatalf("unexpected result. expected=%v, got=%v", tt.expected, res)
+			}
+		})
+	}
+}
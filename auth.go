@@ -0,0 +1,276 @@
+package tracrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/rkl-/digest"
+)
+
+// ErrLoginFailed is returned when the form login is rejected by the server.
+var ErrLoginFailed = errors.New("login failed")
+
+// names of the cookies and the form field used by the login form of Trac.
+const (
+	trac_auth_cookie       = "trac_auth"
+	trac_form_token_cookie = "trac_form_token"
+	trac_form_token_field  = "__FORM_TOKEN"
+)
+
+// authenticator wraps transport to authenticate the requests to rawurl.
+type authenticator func(rawurl string, transport http.RoundTripper) (http.RoundTripper, error)
+
+// WithBasicAuth authenticates the requests with HTTP Basic authentication.
+// The RPC endpoint is usually "/login/rpc" for HTTP authentication.
+func WithBasicAuth(username, password string) Option {
+	return func(o *clientOptions) {
+		o.auth = func(_ string, transport http.RoundTripper) (http.RoundTripper, error) {
+			return &basicAuthTransport{
+				username:  username,
+				password:  password,
+				transport: transport,
+			}, nil
+		}
+	}
+}
+
+// WithDigestAuth authenticates the requests with HTTP Digest authentication.
+// The RPC endpoint is usually "/login/rpc" for HTTP authentication.
+func WithDigestAuth(username, password string) Option {
+	return func(o *clientOptions) {
+		o.auth = func(_ string, transport http.RoundTripper) (http.RoundTripper, error) {
+			return &digest.Transport{
+				Username:  username,
+				Password:  password,
+				Transport: transport,
+			}, nil
+		}
+	}
+}
+
+// WithFormLogin logs in through the login form of Trac (e.g. AccountManagerPlugin)
+// and sends the "trac_auth" session cookie with the requests.
+// The login page is "/login" next to the RPC endpoint, e.g. "http://example.com/trac/login" for "http://example.com/trac/rpc".
+// It logs in again when the session has expired.
+func WithFormLogin(username, password string) Option {
+	return func(o *clientOptions) {
+		o.auth = func(rawurl string, transport http.RoundTripper) (http.RoundTripper, error) {
+			loginURL, err := formLoginURL(rawurl)
+			if err != nil {
+				return nil, err
+			}
+
+			return &formLoginTransport{
+				loginURL:  loginURL,
+				username:  username,
+				password:  password,
+				transport: transport,
+			}, nil
+		}
+	}
+}
+
+// basicAuthTransport represents http.RoundTripper with HTTP Basic authentication.
+type basicAuthTransport struct {
+	username  string
+	password  string
+	transport http.RoundTripper
+}
+
+// RoundTrip sends the request with the Authorization header.
+func (t *basicAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.SetBasicAuth(t.username, t.password)
+
+	return t.transport.RoundTrip(req)
+}
+
+// formLoginTransport represents http.RoundTripper which logs in through the login form.
+type formLoginTransport struct {
+	loginURL  string
+	username  string
+	password  string
+	transport http.RoundTripper
+
+	mu      sync.Mutex
+	session *http.Cookie
+}
+
+// RoundTrip sends the request with the session cookie.
+// If the session has expired, it logs in again and resends the request once.
+func (t *formLoginTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	session, err := t.login(req.Context(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.transport.RoundTrip(withCookie(req, session))
+	if err != nil || !isSessionExpired(resp) {
+		return resp, err
+	}
+	// the body has been consumed and can't be sent again.
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	session, err = t.login(req.Context(), session)
+	if err != nil {
+		return nil, err
+	}
+	retry := withCookie(req, session)
+	if req.Body != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+
+	return t.transport.RoundTrip(retry)
+}
+
+// login returns the current session, or logs in if there is no session or it is the expired one.
+func (t *formLoginTransport) login(ctx context.Context, expired *http.Cookie) (*http.Cookie, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.session != nil && t.session != expired {
+		return t.session, nil
+	}
+	session, err := t.postLoginForm(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t.session = session
+
+	return session, nil
+}
+
+// postLoginForm gets the form token from the login page and posts the credentials with it.
+func (t *formLoginTransport) postLoginForm(ctx context.Context) (*http.Cookie, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := &http.Client{
+		Transport: t.transport,
+		Jar:       jar,
+		// the session cookie is set in the redirect response.
+		CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	u, err := url.Parse(t.loginURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.loginURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := doLoginRequest(httpClient, req); err != nil {
+		return nil, err
+	}
+	token := findCookie(jar.Cookies(u), trac_form_token_cookie)
+	if token == nil {
+		return nil, ErrLoginFailed
+	}
+
+	form := url.Values{
+		"user":                {t.username},
+		"password":            {t.password},
+		trac_form_token_field: {token.Value},
+	}
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, t.loginURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if err := doLoginRequest(httpClient, req); err != nil {
+		return nil, err
+	}
+	session := findCookie(jar.Cookies(u), trac_auth_cookie)
+	if session == nil || session.Value == "" {
+		return nil, ErrLoginFailed
+	}
+
+	return &http.Cookie{Name: session.Name, Value: session.Value}, nil
+}
+
+// doLoginRequest sends the request to the login page and discards the response.
+func doLoginRequest(httpClient *http.Client, req *http.Request) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(ioutil.Discard, resp.Body); err != nil {
+		return err
+	}
+	if resp.StatusCode >= 400 {
+		return &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	return nil
+}
+
+// formLoginURL returns the URL of the login page next to the RPC endpoint.
+func formLoginURL(rawurl string) (string, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return "", err
+	}
+
+	path := strings.TrimRight(u.Path, "/")
+	for _, suffix := range []string{"/rpc", "/xmlrpc", "/jsonrpc"} {
+		if strings.HasSuffix(path, suffix) {
+			path = strings.TrimSuffix(path, suffix)
+			break
+		}
+	}
+	path = strings.TrimSuffix(path, "/login")
+	u.Path = path + "/login"
+	u.RawPath = ""
+	u.RawQuery = ""
+	u.Fragment = ""
+
+	return u.String(), nil
+}
+
+// withCookie returns a copy of req with the cookie.
+func withCookie(req *http.Request, cookie *http.Cookie) *http.Request {
+	req = req.Clone(req.Context())
+	req.AddCookie(cookie)
+
+	return req
+}
+
+// isSessionExpired checks the response denies the session.
+// Trac expires the "trac_auth" cookie when the session is not valid anymore and handles the request as anonymous,
+// so 403 without expiring the cookie means the user lacks the permission, which logging in again doesn't fix.
+func isSessionExpired(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusUnauthorized {
+		return true
+	}
+	cookie := findCookie(resp.Cookies(), trac_auth_cookie)
+
+	return cookie != nil && (cookie.Value == "" || cookie.MaxAge < 0)
+}
+
+// findCookie finds the cookie by name.
+func findCookie(cookies []*http.Cookie, name string) *http.Cookie {
+	for _, cookie := range cookies {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,232 @@
+package tracrpc
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+const stringReply = `<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><string>Shiga</string></value>
+</param>
+</params>
+</methodResponse>`
+
+func newStringResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     make(http.Header),
+		Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+	}
+}
+
+func TestWithBasicAuth(t *testing.T) {
+	c, err := NewClient(
+		"http://example.com/login/rpc",
+		RoundTripFunc(func(req *http.Request) *http.Response {
+			if username, password, ok := req.BasicAuth(); !ok || username != "admin" || password != "secret" {
+				return newStringResponse(http.StatusUnauthorized, "")
+			}
+			return newStringResponse(http.StatusOK, stringReply)
+		}),
+		WithBasicAuth("admin", "secret"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Wiki.GetPage(String("shiga"), nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// formLoginServer represents the fake server with the login form.
+type formLoginServer struct {
+	sessions   int
+	logins     int
+	rpcCalls   int
+	expireOnce bool
+	dropOnce   bool
+	forbidden  bool
+}
+
+func (s *formLoginServer) RoundTrip(req *http.Request) *http.Response {
+	switch {
+	case req.URL.Path == "/trac/login" && req.Method == http.MethodGet:
+		resp := newStringResponse(http.StatusOK, "<form></form>")
+		resp.Header.Add("Set-Cookie", "trac_form_token=token; Path=/trac")
+		return resp
+	case req.URL.Path == "/trac/login" && req.Method == http.MethodPost:
+		if token, err := req.Cookie("trac_form_token"); err != nil || token.Value != "token" {
+			return newStringResponse(http.StatusBadRequest, "")
+		}
+		req.ParseForm()
+		if req.PostForm.Get("__FORM_TOKEN") != "token" || req.PostForm.Get("user") != "admin" || req.PostForm.Get("password") != "secret" {
+			return newStringResponse(http.StatusOK, "<form></form>")
+		}
+		s.logins++
+		s.sessions++
+		resp := newStringResponse(http.StatusSeeOther, "")
+		resp.Header.Set("Location", "/trac")
+		resp.Header.Add("Set-Cookie", "trac_auth=session"+strconv.Itoa(s.sessions)+"; Path=/trac")
+		return resp
+	case req.URL.Path == "/trac/rpc":
+		s.rpcCalls++
+		if s.dropOnce {
+			// the server forgets the session, e.g. by the restart.
+			s.dropOnce = false
+			s.sessions++
+		}
+		session, err := req.Cookie("trac_auth")
+		if err != nil {
+			return newStringResponse(http.StatusForbidden, "")
+		}
+		if session.Value != "session"+strconv.Itoa(s.sessions) {
+			// Trac expires the invalid session and denies the anonymous user.
+			resp := newStringResponse(http.StatusForbidden, "")
+			resp.Header.Add("Set-Cookie", "trac_auth=; Path=/trac; expires=Thu, 01 Jan 1970 00:00:00 GMT")
+			return resp
+		}
+		if s.forbidden {
+			return newStringResponse(http.StatusForbidden, "")
+		}
+		if s.expireOnce {
+			s.expireOnce = false
+			s.sessions++
+			resp := newStringResponse(http.StatusOK, stringReply)
+			resp.Header.Add("Set-Cookie", "trac_auth=; Path=/trac; expires=Thu, 01 Jan 1970 00:00:00 GMT")
+			return resp
+		}
+		return newStringResponse(http.StatusOK, stringReply)
+	default:
+		return newStringResponse(http.StatusNotFound, "")
+	}
+}
+
+func TestWithFormLogin(t *testing.T) {
+	tests := []struct {
+		name             string
+		password         string
+		expireOnce       bool
+		dropOnce         bool
+		expectedLogins   int
+		expectedRpcCalls int
+		expectedErr      error
+	}{
+		{
+			name:             "LoggedIn",
+			password:         "secret",
+			expectedLogins:   1,
+			expectedRpcCalls: 2,
+		},
+		{
+			name:             "Expired",
+			password:         "secret",
+			expireOnce:       true,
+			expectedLogins:   2,
+			expectedRpcCalls: 3,
+		},
+		{
+			name:             "Dropped",
+			password:         "secret",
+			dropOnce:         true,
+			expectedLogins:   2,
+			expectedRpcCalls: 3,
+		},
+		{
+			name:             "WrongPassword",
+			password:         "wrong",
+			expectedLogins:   0,
+			expectedRpcCalls: 0,
+			expectedErr:      ErrLoginFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &formLoginServer{expireOnce: tt.expireOnce, dropOnce: tt.dropOnce}
+			c, err := NewClient(
+				"http://example.com/trac/rpc",
+				RoundTripFunc(server.RoundTrip),
+				WithFormLogin("admin", tt.password),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < 2; i++ {
+				res, err := c.Wiki.GetPage(String("shiga"), nil)
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("unexpected error. expected=%v, got=%v", tt.expectedErr, err)
+				}
+				if err == nil && res != "Shiga" {
+					t.Fatalf("unexpected result. expected=%v, got=%v", "Shiga", res)
+				}
+			}
+			if server.logins != tt.expectedLogins {
+				t.Fatalf("unexpected number of logins. expected=%v, got=%v", tt.expectedLogins, server.logins)
+			}
+			if server.rpcCalls != tt.expectedRpcCalls {
+				t.Fatalf("unexpected number of calls. expected=%v, got=%v", tt.expectedRpcCalls, server.rpcCalls)
+			}
+		})
+	}
+}
+
+func TestWithFormLoginForbidden(t *testing.T) {
+	server := &formLoginServer{forbidden: true}
+	c, err := NewClient(
+		"http://example.com/trac/rpc",
+		RoundTripFunc(server.RoundTrip),
+		WithFormLogin("admin", "secret"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := c.Wiki.GetPage(String("shiga"), nil)
+		var statusErr *StatusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusForbidden {
+			t.Fatalf("unexpected error. expected=%v, got=%v", &StatusError{StatusCode: http.StatusForbidden}, err)
+		}
+	}
+	// the user lacking the permission doesn't log in again.
+	if server.logins != 1 {
+		t.Fatalf("unexpected number of logins. expected=%v, got=%v", 1, server.logins)
+	}
+	if server.rpcCalls != 2 {
+		t.Fatalf("unexpected number of calls. expected=%v, got=%v", 2, server.rpcCalls)
+	}
+}
+
+func TestFormLoginURL(t *testing.T) {
+	tests := []struct {
+		rawurl   string
+		expected string
+	}{
+		{"http://example.com/trac/rpc", "http://example.com/trac/login"},
+		{"http://example.com/trac/login/rpc", "http://example.com/trac/login"},
+		{"http://example.com/trac/login/xmlrpc", "http://example.com/trac/login"},
+		{"http://example.com/trac/jsonrpc/", "http://example.com/trac/login"},
+		{"http://example.com/", "http://example.com/login"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rawurl, func(t *testing.T) {
+			res, err := formLoginURL(tt.rawurl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res != tt.expected {
+				t.Fatalf("unexpected result. expected=%v, got=%v", tt.expected, res)
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package tracrpc
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"net/http/cookiejar"
 	"reflect"
 
 	"github.com/kolo/xmlrpc"
@@ -9,40 +12,173 @@ import (
 
 // Client replresents trac API client.
 type Client struct {
+	rpc    RpcClient
 	Search *SearchService
 	System *SystemService
+	Ticket *TicketService
 	Wiki   *WikiService
 }
 
 // base64String represents Base64-Encoded bytes.
 type base64String = xmlrpc.Base64
 
+// Protocol represents the RPC protocol of the server.
+type Protocol int
+
+const (
+	// ProtocolXMLRPC is XML-RPC, served at "/rpc" or "/xmlrpc".
+	ProtocolXMLRPC Protocol = iota
+	// ProtocolJSONRPC is JSON-RPC, served at "/rpc" or "/jsonrpc".
+	ProtocolJSONRPC
+)
+
+// Option represents an option of NewClient.
+type Option func(*clientOptions)
+
+// clientOptions represents the options of NewClient.
+type clientOptions struct {
+	protocol     Protocol
+	interceptors []Interceptor
+	auth         authenticator
+}
+
+// WithProtocol sets the RPC protocol. The default is ProtocolXMLRPC.
+func WithProtocol(protocol Protocol) Option {
+	return func(o *clientOptions) {
+		o.protocol = protocol
+	}
+}
+
 // NewClient creates new Client
-func NewClient(url string, transport http.RoundTripper) (*Client, error) {
-	xmlrpcClient, err := xmlrpc.NewClient(url, transport)
+func NewClient(url string, transport http.RoundTripper, opts ...Option) (*Client, error) {
+	options := clientOptions{
+		protocol: ProtocolXMLRPC,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.auth != nil {
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		authTransport, err := options.auth(url, transport)
+		if err != nil {
+			return nil, err
+		}
+		transport = authTransport
+	}
+
+	var rpc RpcClient
+	switch options.protocol {
+	case ProtocolXMLRPC:
+		xmlrpcClient, err := newXmlrpcClient(url, transport)
+		if err != nil {
+			return nil, err
+		}
+		rpc = xmlrpcClient
+	case ProtocolJSONRPC:
+		jsonrpcClient, err := newJsonrpcClient(url, transport)
+		if err != nil {
+			return nil, err
+		}
+		rpc = jsonrpcClient
+	default:
+		return nil, fmt.Errorf("unknown protocol %d", options.protocol)
+	}
+	if len(options.interceptors) > 0 {
+		rpc = newInterceptedRpcClient(rpc, options.interceptors)
+	}
+
+	return newClient(rpc)
+}
+
+// newClient creates new Client which calls APIs through rpc.
+func newClient(rpc RpcClient) (*Client, error) {
+	search, err := newSearchService(rpc)
 	if err != nil {
 		return nil, err
 	}
-	search, err := newSearchService(xmlrpcClient)
+	system, err := newSystemService(rpc)
 	if err != nil {
 		return nil, err
 	}
-	system, err := newSystemService(xmlrpcClient)
+	ticket, err := newTicketService(rpc)
 	if err != nil {
 		return nil, err
 	}
-	wiki, err := newWikiService(xmlrpcClient)
+	wiki, err := newWikiService(rpc)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Client{
+		rpc:    rpc,
 		Search: search,
 		System: system,
+		Ticket: ticket,
 		Wiki:   wiki,
 	}, nil
 }
 
+// newHTTPClient creates new http.Client which keeps cookies.
+func newHTTPClient(transport http.RoundTripper) (*http.Client, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Jar:       jar,
+	}, nil
+}
+
+// WithContext returns a copy of c whose API calls are made with ctx.
+// The calls are canceled and return ctx.Err() when ctx is done.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	if ctx == nil {
+		panic("nil context")
+	}
+
+	client, err := newClient(&contextRpcClient{ctx: ctx, rpc: c.rpc})
+	if err != nil {
+		// never happens since the rpc client is not nil.
+		panic(err)
+	}
+	// keep the original so that WithContext of the copy doesn't nest contexts.
+	client.rpc = c.rpc
+
+	return client
+}
+
+// contextRpcClient represents RpcClient which calls with the context.
+type contextRpcClient struct {
+	ctx context.Context
+	rpc RpcClient
+}
+
+// Call calls the method with the context.
+func (c *contextRpcClient) Call(methodName string, args interface{}, reply interface{}) error {
+	return callContext(c.ctx, c.rpc, methodName, args, reply)
+}
+
+// callContext calls the method of rpc with ctx.
+// If rpc doesn't support context, ctx is only checked before the call.
+func callContext(ctx context.Context, rpc RpcClient, methodName string, args interface{}, reply interface{}) error {
+	if rpc, ok := rpc.(ContextRpcClient); ok {
+		return rpc.CallContext(ctx, methodName, args, reply)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return rpc.Call(methodName, args, reply)
+}
+
 // packArgs packs args into []interface{}.
 // Args must be pointers.
 func packArgs(args ...interface{}) []interface{} {
@@ -0,0 +1,231 @@
+// Command tracctl calls Trac RPC APIs from the command line.
+//
+// Usage:
+//
+//	tracctl [global flags] <command> <subcommand> [flags] [args]
+//
+// The URL and the credentials are taken from the flags, or the environment variables
+// TRAC_URL, TRAC_USER, TRAC_PASSWORD and TRAC_AUTH.
+// Flags of the commands must precede the args, e.g. "tracctl wiki get -version 2 WikiStart".
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/f-velka/tracrpc"
+)
+
+const usage = `Usage: tracctl [global flags] <command> <subcommand> [flags] [args]
+
+Commands:
+  wiki get [-version N] PAGE                    print the text of the page
+  wiki put [-file FILE] [-comment C] PAGE       write the page from the file or stdin
+  wiki ls                                       list all pages
+  wiki rm [-version N] PAGE                     delete the page or its version
+  wiki info [-version N] PAGE                   print the info of the page
+  wiki history PAGE                             print the versions of the page
+  wiki attach ls PAGE                           list the attachments of the page
+  wiki attach get [-out FILE] PAGE/FILENAME     download the attachment
+  wiki attach put [-description D] PAGE FILE    upload the file
+  wiki attach rm PAGE/FILENAME                  delete the attachment
+  ticket get ID                                 print the ticket
+  ticket create [-description D] [-attr K=V]... SUMMARY
+                                                create a ticket and print its id
+  ticket update [-comment C] [-action A] [-input K=V]... [-attr K=V]... ID
+                                                update the ticket
+  ticket query [-order F] [-desc] [-max N] [-page N] [CONDITION]...
+                                                print the ids of the tickets, e.g. "status!=closed" "owner=jdoe|ii"
+  ticket log [-group] ID                        print the changelog of the ticket
+  search [-filter NAME]... QUERY                search the wiki and the tickets
+  system methods                                list the methods of the server
+  system help METHOD                            print the help of the method
+
+Global flags:
+`
+
+// errUsage is returned when the command line is wrong.
+var errUsage = errors.New("invalid usage")
+
+// command represents the parsed global flags and the output.
+type command struct {
+	client *tracrpc.Client
+	stdin  io.Reader
+	stdout io.Writer
+	format string
+}
+
+// stringsFlag represents a flag which can be repeated.
+type stringsFlag []string
+
+// String implements flag.Value.
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+// Set implements flag.Value.
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr, os.Getenv))
+}
+
+// run runs the command line and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer, getenv func(string) string) int {
+	flags := flag.NewFlagSet("tracctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+	url := flags.String("url", getenv("TRAC_URL"), "RPC endpoint, e.g. http://example.com/trac/login/rpc ($TRAC_URL)")
+	// the credentials are read from the environment after parsing, so that the usage doesn't show them.
+	user := flags.String("user", "", "user name ($TRAC_USER)")
+	password := flags.String("password", "", "password ($TRAC_PASSWORD)")
+	auth := flags.String("auth", getenvDefault(getenv, "TRAC_AUTH", "basic"), "authentication: basic, digest or form ($TRAC_AUTH)")
+	protocol := flags.String("protocol", "xmlrpc", "RPC protocol: xmlrpc or jsonrpc")
+	format := flags.String("o", "text", "output format: text or json")
+	timeout := flags.Duration("timeout", 30*time.Second, "timeout of the command")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *user == "" {
+		*user = getenv("TRAC_USER")
+	}
+	if *password == "" {
+		*password = getenv("TRAC_PASSWORD")
+	}
+	if *url == "" || flags.NArg() == 0 || (*format != "text" && *format != "json") {
+		flags.Usage()
+		return 2
+	}
+
+	opts := []tracrpc.Option{}
+	switch *protocol {
+	case "xmlrpc":
+	case "jsonrpc":
+		opts = append(opts, tracrpc.WithProtocol(tracrpc.ProtocolJSONRPC))
+	default:
+		fmt.Fprintf(stderr, "tracctl: unknown protocol %s\n", *protocol)
+		return 2
+	}
+	if *user != "" {
+		switch *auth {
+		case "basic":
+			opts = append(opts, tracrpc.WithBasicAuth(*user, *password))
+		case "digest":
+			opts = append(opts, tracrpc.WithDigestAuth(*user, *password))
+		case "form":
+			opts = append(opts, tracrpc.WithFormLogin(*user, *password))
+		default:
+			fmt.Fprintf(stderr, "tracctl: unknown authentication %s\n", *auth)
+			return 2
+		}
+	}
+	client, err := tracrpc.NewClient(*url, nil, opts...)
+	if err != nil {
+		fmt.Fprintf(stderr, "tracctl: %v\n", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	cmd := &command{
+		client: client.WithContext(ctx),
+		stdin:  stdin,
+		stdout: stdout,
+		format: *format,
+	}
+
+	if err := cmd.run(flags.Args()); err != nil {
+		if errors.Is(err, errUsage) {
+			flags.Usage()
+			return 2
+		}
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(stderr, "tracctl: %v\n", err)
+		}
+		return 1
+	}
+
+	return 0
+}
+
+// run dispatches the command.
+func (c *command) run(args []string) error {
+	switch args[0] {
+	case "wiki":
+		return c.wiki(args[1:])
+	case "ticket":
+		return c.ticket(args[1:])
+	case "search":
+		return c.search(args[1:])
+	case "system":
+		return c.system(args[1:])
+	}
+
+	return errUsage
+}
+
+// parseFlags parses the flags of the subcommand and checks the number of the args.
+func parseFlags(flags *flag.FlagSet, args []string, minArgs, maxArgs int) error {
+	flags.SetOutput(io.Discard)
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%s: %v: %w", flags.Name(), err, errUsage)
+	}
+	if flags.NArg() < minArgs || (maxArgs >= 0 && flags.NArg() > maxArgs) {
+		return fmt.Errorf("%s: wrong number of args: %w", flags.Name(), errUsage)
+	}
+
+	return nil
+}
+
+// print prints v as JSON, or calls text to print it as text.
+func (c *command) print(v interface{}, text func(w io.Writer)) error {
+	if c.format == "json" {
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	text(c.stdout)
+
+	return nil
+}
+
+// parseKeyValues parses "key=value" pairs.
+func parseKeyValues(pairs []string) (map[string]string, error) {
+	values := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		i := strings.Index(pair, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("%s is not KEY=VALUE: %w", pair, errUsage)
+		}
+		values[pair[:i]] = pair[i+1:]
+	}
+
+	return values, nil
+}
+
+// getenvDefault returns the environment variable, or def if it is empty.
+func getenvDefault(getenv func(string) string, key, def string) string {
+	if value := getenv(key); value != "" {
+		return value
+	}
+
+	return def
+}
+
+// formatTime formats the time in the local time zone.
+func formatTime(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04:05")
+}
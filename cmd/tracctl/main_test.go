@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/f-velka/tracrpc/tractest"
+)
+
+func TestRun(t *testing.T) {
+	srv := tractest.NewServer()
+	defer srv.Close()
+	srv.AddPage("WikiStart", "= Welcome =", "trac", "")
+	srv.AddTicket("sakuradamon", "ouch", "ii", map[string]string{"owner": "ii"})
+	env := map[string]string{
+		"TRAC_URL":  srv.URL + "/login/rpc",
+		"TRAC_USER": "admin",
+	}
+
+	tests := []struct {
+		name             string
+		args             []string
+		stdin            string
+		expectedCode     int
+		expectedContains string
+	}{
+		{"WikiGet", []string{"wiki", "get", "WikiStart"}, "", 0, "= Welcome ="},
+		{"WikiPut", []string{"wiki", "put", "-comment", "shiga", "Shiga"}, "biwako", 0, ""},
+		{"WikiInfo", []string{"-o", "json", "wiki", "info", "Shiga"}, "", 0, `"Author": "admin"`},
+		{"WikiList", []string{"wiki", "ls"}, "", 0, "Shiga\nWikiStart\n"},
+		{"WikiNotFound", []string{"wiki", "get", "Kyoto"}, "", 1, ""},
+		{"TicketQuery", []string{"ticket", "query", "owner=ii|naosuke", "summary~=sakura"}, "", 0, "1\n"},
+		{"TicketUpdate", []string{"ticket", "update", "-action", "resolve", "-input", "action_resolve_resolve_resolution=wontfix", "-comment", "done", "#1"}, "", 0, "resolution: wontfix"},
+		{"TicketLog", []string{"ticket", "log", "-group", "1"}, "", 0, "  done"},
+		{"Search", []string{"-o", "json", "search", "biwako"}, "", 0, `"Href": "/wiki/Shiga"`},
+		{"SystemHelp", []string{"system", "help", "wiki.getPage"}, "", 0, "wiki.getPage(string,string,int)"},
+		{"Usage", []string{"wiki", "mv"}, "", 2, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr, func(key string) string { return env[key] })
+			if code != tt.expectedCode {
+				t.Fatalf("unexpected exit code. expected=%v, got=%v, stderr=%s", tt.expectedCode, code, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.expectedContains) {
+				t.Fatalf("unexpected output. expected to contain %q, got=%q", tt.expectedContains, stdout.String())
+			}
+		})
+	}
+}
+
+func TestRunUsageHidesCredentials(t *testing.T) {
+	env := map[string]string{
+		"TRAC_URL":      "http://example.com/login/rpc",
+		"TRAC_USER":     "naosuke",
+		"TRAC_PASSWORD": "hunter2",
+	}
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"UnknownCommand", []string{"wiki", "mv"}},
+		{"UnknownFlag", []string{"-verbose", "wiki", "ls"}},
+		{"Help", []string{"-h"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, strings.NewReader(""), &stdout, &stderr, func(key string) string { return env[key] })
+			if code != 2 {
+				t.Fatalf("unexpected exit code. expected=%v, got=%v, stderr=%s", 2, code, stderr.String())
+			}
+			for _, secret := range []string{"hunter2", "naosuke"} {
+				if strings.Contains(stderr.String(), secret) {
+					t.Fatalf("unexpected output. expected not to contain %q, got=%q", secret, stderr.String())
+				}
+			}
+		})
+	}
+}
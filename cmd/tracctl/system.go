@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/f-velka/tracrpc"
+)
+
+func (c *command) search(args []string) error {
+	flags := flag.NewFlagSet("search", flag.ContinueOnError)
+	var filters stringsFlag
+	flags.Var(&filters, "filter", "search filter, e.g. wiki or ticket. can be repeated")
+	if err := parseFlags(flags, args, 1, -1); err != nil {
+		return err
+	}
+
+	results, err := c.client.Search.PerformSearch(tracrpc.String(strings.Join(flags.Args(), " ")), filters)
+	if err != nil {
+		return err
+	}
+
+	return c.print(results, func(w io.Writer) {
+		for _, result := range results {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.Href, result.Title, formatTime(result.Date), result.Author)
+		}
+	})
+}
+
+// system runs the system commands.
+func (c *command) system(args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "methods":
+		return c.systemMethods(args[1:])
+	case "help":
+		return c.systemHelp(args[1:])
+	}
+
+	return errUsage
+}
+
+func (c *command) systemMethods(args []string) error {
+	flags := flag.NewFlagSet("system methods", flag.ContinueOnError)
+	if err := parseFlags(flags, args, 0, 0); err != nil {
+		return err
+	}
+
+	methods, err := c.client.System.ListMethods()
+	if err != nil {
+		return err
+	}
+
+	return c.print(methods, func(w io.Writer) {
+		for _, method := range methods {
+			fmt.Fprintln(w, method)
+		}
+	})
+}
+
+func (c *command) systemHelp(args []string) error {
+	flags := flag.NewFlagSet("system help", flag.ContinueOnError)
+	if err := parseFlags(flags, args, 1, 1); err != nil {
+		return err
+	}
+
+	method := tracrpc.String(flags.Arg(0))
+	help, err := c.client.System.MethodHelp(method)
+	if err != nil {
+		return err
+	}
+	signature, err := c.client.System.MethodSignature(method)
+	if err != nil {
+		return err
+	}
+
+	return c.print(map[string]interface{}{"help": help, "signature": signature}, func(w io.Writer) {
+		for _, s := range signature {
+			fmt.Fprintf(w, "%s(%s)\n", *method, s)
+		}
+		fmt.Fprintf(w, "\n%s\n", help)
+	})
+}
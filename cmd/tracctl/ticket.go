@@ -0,0 +1,267 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/f-velka/tracrpc"
+)
+
+// queryOperators are the operators of the conditions, the longer first.
+var queryOperators = []tracrpc.QueryOperator{
+	tracrpc.QueryNotContains,
+	tracrpc.QueryNotStartsWith,
+	tracrpc.QueryNotEndsWith,
+	tracrpc.QueryIsNot,
+	tracrpc.QueryContains,
+	tracrpc.QueryStartsWith,
+	tracrpc.QueryEndsWith,
+	tracrpc.QueryIs,
+}
+
+// ticket runs the ticket commands.
+func (c *command) ticket(args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "get":
+		return c.ticketGet(args[1:])
+	case "create":
+		return c.ticketCreate(args[1:])
+	case "update":
+		return c.ticketUpdate(args[1:])
+	case "query":
+		return c.ticketQuery(args[1:])
+	case "log":
+		return c.ticketLog(args[1:])
+	}
+
+	return errUsage
+}
+
+// ticketID parses the ticket id, which may be prefixed with "#".
+func ticketID(arg string) (int, error) {
+	id, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid ticket id %s: %w", arg, errUsage)
+	}
+
+	return id, nil
+}
+
+func (c *command) ticketGet(args []string) error {
+	flags := flag.NewFlagSet("ticket get", flag.ContinueOnError)
+	if err := parseFlags(flags, args, 1, 1); err != nil {
+		return err
+	}
+	id, err := ticketID(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	ticket, err := c.client.Ticket.Get(&id)
+	if err != nil {
+		return err
+	}
+
+	return c.printTicket(ticket)
+}
+
+func (c *command) ticketCreate(args []string) error {
+	flags := flag.NewFlagSet("ticket create", flag.ContinueOnError)
+	description := flags.String("description", "", "description of the ticket")
+	var attrs stringsFlag
+	flags.Var(&attrs, "attr", "attribute of the ticket as KEY=VALUE. can be repeated")
+	if err := parseFlags(flags, args, 1, 1); err != nil {
+		return err
+	}
+	attributes, err := parseAttributes(attrs)
+	if err != nil {
+		return err
+	}
+
+	id, err := c.client.Ticket.Create(tracrpc.String(flags.Arg(0)), description, attributes, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.print(id, func(w io.Writer) {
+		fmt.Fprintln(w, id)
+	})
+}
+
+func (c *command) ticketUpdate(args []string) error {
+	flags := flag.NewFlagSet("ticket update", flag.ContinueOnError)
+	comment := flags.String("comment", "", "comment of the change")
+	action := flags.String("action", "", "workflow action, e.g. resolve")
+	var attrs, inputs stringsFlag
+	flags.Var(&attrs, "attr", "attribute to change as KEY=VALUE. can be repeated")
+	flags.Var(&inputs, "input", "input field of the action as KEY=VALUE. can be repeated")
+	if err := parseFlags(flags, args, 1, 1); err != nil {
+		return err
+	}
+	id, err := ticketID(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	attributes, err := parseAttributes(attrs)
+	if err != nil {
+		return err
+	}
+	inputValues, err := parseKeyValues(inputs)
+	if err != nil {
+		return err
+	}
+
+	var ticket tracrpc.Ticket
+	if *action != "" {
+		ticket, err = c.client.Ticket.UpdateWithAction(&id, comment, *action, inputValues, attributes, nil, nil, nil)
+	} else if len(inputValues) > 0 {
+		return fmt.Errorf("-input requires -action: %w", errUsage)
+	} else {
+		ticket, err = c.client.Ticket.Update(&id, comment, attributes, nil, nil, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.printTicket(ticket)
+}
+
+func (c *command) ticketQuery(args []string) error {
+	flags := flag.NewFlagSet("ticket query", flag.ContinueOnError)
+	order := flags.String("order", "", "field to sort by")
+	desc := flags.Bool("desc", false, "sort in descending order")
+	max := flags.Int("max", -1, "maximum number of tickets. 0 for all")
+	page := flags.Int("page", 0, "page of the results")
+	if err := parseFlags(flags, args, 0, -1); err != nil {
+		return err
+	}
+
+	query := tracrpc.NewTicketQuery()
+	for _, cond := range flags.Args() {
+		if err := addCondition(query, cond); err != nil {
+			return err
+		}
+	}
+	if *order != "" {
+		query.OrderBy(*order, *desc)
+	}
+	if *max >= 0 {
+		query.Max(*max)
+	}
+	if *page > 0 {
+		query.Page(*page)
+	}
+
+	ids, err := c.client.Ticket.Query(query)
+	if err != nil {
+		return err
+	}
+
+	return c.print(ids, func(w io.Writer) {
+		for _, id := range ids {
+			fmt.Fprintln(w, id)
+		}
+	})
+}
+
+func (c *command) ticketLog(args []string) error {
+	flags := flag.NewFlagSet("ticket log", flag.ContinueOnError)
+	group := flags.Bool("group", false, "group the changes made at the same time by the same author")
+	if err := parseFlags(flags, args, 1, 1); err != nil {
+		return err
+	}
+	id, err := ticketID(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	changes, err := c.client.Ticket.ChangeLog(&id)
+	if err != nil {
+		return err
+	}
+
+	if *group {
+		sets := tracrpc.GroupTicketChanges(changes)
+		return c.print(sets, func(w io.Writer) {
+			for _, set := range sets {
+				fmt.Fprintf(w, "%s %s\n", formatTime(set.Time), set.Author)
+				for _, change := range set.Changes {
+					if change.Field != "comment" {
+						fmt.Fprintf(w, "  %s: %s -> %s\n", change.Field, change.OldValue, change.NewValue)
+					}
+				}
+				if comment := set.Comment(); comment != "" {
+					fmt.Fprintf(w, "  %s\n", strings.ReplaceAll(comment, "\n", "\n  "))
+				}
+			}
+		})
+	}
+
+	return c.print(changes, func(w io.Writer) {
+		for _, change := range changes {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%q\t%q\n", formatTime(change.Time), change.Author, change.Field, change.OldValue, change.NewValue)
+		}
+	})
+}
+
+// printTicket prints the ticket with the attributes in order of name.
+func (c *command) printTicket(ticket tracrpc.Ticket) error {
+	return c.print(ticket, func(w io.Writer) {
+		fmt.Fprintf(w, "ID:       %d\n", ticket.ID)
+		fmt.Fprintf(w, "Created:  %s\n", formatTime(ticket.TimeCreated))
+		fmt.Fprintf(w, "Modified: %s\n", formatTime(ticket.TimeChanged))
+		names := make([]string, 0, len(ticket.Attributes))
+		for name := range ticket.Attributes {
+			if name != "description" && !strings.HasPrefix(name, "_") {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(w, "%s: %v\n", name, ticket.Attributes[name])
+		}
+		if description, ok := ticket.Attributes["description"]; ok {
+			fmt.Fprintf(w, "\n%v\n", description)
+		}
+	})
+}
+
+// parseAttributes parses "key=value" pairs into ticket attributes.
+func parseAttributes(pairs []string) (map[string]interface{}, error) {
+	values, err := parseKeyValues(pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := make(map[string]interface{}, len(values))
+	for name, value := range values {
+		attributes[name] = value
+	}
+
+	return attributes, nil
+}
+
+// addCondition parses "field<op>value|value" and adds it to the query.
+func addCondition(query *tracrpc.TicketQuery, cond string) error {
+	i := strings.Index(cond, "=")
+	if i <= 0 {
+		return fmt.Errorf("invalid condition %s: %w", cond, errUsage)
+	}
+	for _, op := range queryOperators {
+		if strings.HasSuffix(cond[:i+1], string(op)) {
+			field := cond[:i+1-len(op)]
+			query.Where(field, op, strings.Split(cond[i+1:], "|")...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid condition %s: %w", cond, errUsage)
+}
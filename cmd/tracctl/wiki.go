@@ -0,0 +1,280 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/f-velka/tracrpc"
+)
+
+// wiki runs the wiki commands.
+func (c *command) wiki(args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "get":
+		return c.wikiGet(args[1:])
+	case "put":
+		return c.wikiPut(args[1:])
+	case "ls":
+		return c.wikiList(args[1:])
+	case "rm":
+		return c.wikiRemove(args[1:])
+	case "info":
+		return c.wikiInfo(args[1:])
+	case "history":
+		return c.wikiHistory(args[1:])
+	case "attach":
+		return c.wikiAttach(args[1:])
+	}
+
+	return errUsage
+}
+
+// versionFlag returns the version flag, which is nil if not given.
+func versionFlag(flags *flag.FlagSet) func() *int {
+	version := flags.Int("version", 0, "version of the page")
+	return func() *int {
+		if *version == 0 {
+			return nil
+		}
+		return version
+	}
+}
+
+func (c *command) wikiGet(args []string) error {
+	flags := flag.NewFlagSet("wiki get", flag.ContinueOnError)
+	version := versionFlag(flags)
+	if err := parseFlags(flags, args, 1, 1); err != nil {
+		return err
+	}
+
+	text, err := c.client.Wiki.GetPage(tracrpc.String(flags.Arg(0)), version())
+	if err != nil {
+		return err
+	}
+
+	return c.print(text, func(w io.Writer) {
+		fmt.Fprint(w, text)
+	})
+}
+
+func (c *command) wikiPut(args []string) error {
+	flags := flag.NewFlagSet("wiki put", flag.ContinueOnError)
+	file := flags.String("file", "", "file of the text. stdin is read if not given")
+	comment := flags.String("comment", "", "comment of the change")
+	if err := parseFlags(flags, args, 1, 1); err != nil {
+		return err
+	}
+
+	var text []byte
+	var err error
+	if *file == "" {
+		text, err = ioutil.ReadAll(c.stdin)
+	} else {
+		text, err = ioutil.ReadFile(*file)
+	}
+	if err != nil {
+		return err
+	}
+	attributes := tracrpc.PutPageAttributes{}
+	if *comment != "" {
+		attributes.Comment = comment
+	}
+	ok, err := c.client.Wiki.PutPage(tracrpc.String(flags.Arg(0)), tracrpc.String(string(text)), attributes)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("the page was not saved")
+	}
+
+	return c.print(ok, func(w io.Writer) {})
+}
+
+func (c *command) wikiList(args []string) error {
+	flags := flag.NewFlagSet("wiki ls", flag.ContinueOnError)
+	if err := parseFlags(flags, args, 0, 0); err != nil {
+		return err
+	}
+
+	pages, err := c.client.Wiki.GetAllPages()
+	if err != nil {
+		return err
+	}
+
+	return c.print(pages, func(w io.Writer) {
+		for _, page := range pages {
+			fmt.Fprintln(w, page)
+		}
+	})
+}
+
+func (c *command) wikiRemove(args []string) error {
+	flags := flag.NewFlagSet("wiki rm", flag.ContinueOnError)
+	version := versionFlag(flags)
+	if err := parseFlags(flags, args, 1, 1); err != nil {
+		return err
+	}
+
+	ok, err := c.client.Wiki.DeletePage(tracrpc.String(flags.Arg(0)), version())
+	if err != nil {
+		return err
+	}
+
+	return c.print(ok, func(w io.Writer) {})
+}
+
+func (c *command) wikiInfo(args []string) error {
+	flags := flag.NewFlagSet("wiki info", flag.ContinueOnError)
+	version := versionFlag(flags)
+	if err := parseFlags(flags, args, 1, 1); err != nil {
+		return err
+	}
+
+	info, err := c.client.Wiki.GetPageInfo(tracrpc.String(flags.Arg(0)), version())
+	if err != nil {
+		return err
+	}
+
+	return c.print(info, func(w io.Writer) {
+		fmt.Fprintf(w, "Name:     %s\n", info.Name)
+		fmt.Fprintf(w, "Version:  %d\n", info.Version)
+		fmt.Fprintf(w, "Modified: %s\n", formatTime(info.LastModified))
+		fmt.Fprintf(w, "Author:   %s\n", info.Author)
+		fmt.Fprintf(w, "Comment:  %s\n", info.Comment)
+	})
+}
+
+func (c *command) wikiHistory(args []string) error {
+	flags := flag.NewFlagSet("wiki history", flag.ContinueOnError)
+	if err := parseFlags(flags, args, 1, 1); err != nil {
+		return err
+	}
+
+	name := tracrpc.String(flags.Arg(0))
+	latest, err := c.client.Wiki.GetPageInfo(name, nil)
+	if err != nil {
+		return err
+	}
+	history := []tracrpc.PageInfo{latest}
+	for version := latest.Version - 1; version > 0; version-- {
+		info, err := c.client.Wiki.GetPageInfoVersion(name, tracrpc.Int(version))
+		if errors.Is(err, tracrpc.ErrNotFound) {
+			// the version has been deleted.
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		history = append(history, info)
+	}
+
+	return c.print(history, func(w io.Writer) {
+		for _, info := range history {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", info.Version, formatTime(info.LastModified), info.Author, info.Comment)
+		}
+	})
+}
+
+// wikiAttach runs the wiki attach commands.
+func (c *command) wikiAttach(args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "ls":
+		return c.wikiAttachList(args[1:])
+	case "get":
+		return c.wikiAttachGet(args[1:])
+	case "put":
+		return c.wikiAttachPut(args[1:])
+	case "rm":
+		return c.wikiAttachRemove(args[1:])
+	}
+
+	return errUsage
+}
+
+func (c *command) wikiAttachList(args []string) error {
+	flags := flag.NewFlagSet("wiki attach ls", flag.ContinueOnError)
+	if err := parseFlags(flags, args, 1, 1); err != nil {
+		return err
+	}
+
+	paths, err := c.client.Wiki.ListAttachments(tracrpc.String(flags.Arg(0)))
+	if err != nil {
+		return err
+	}
+
+	return c.print(paths, func(w io.Writer) {
+		for _, path := range paths {
+			fmt.Fprintln(w, path)
+		}
+	})
+}
+
+func (c *command) wikiAttachGet(args []string) error {
+	flags := flag.NewFlagSet("wiki attach get", flag.ContinueOnError)
+	out := flags.String("out", "", "file to save. stdout is written if not given")
+	if err := parseFlags(flags, args, 1, 1); err != nil {
+		return err
+	}
+
+	data, err := c.client.Wiki.GetAttachment(tracrpc.String(flags.Arg(0)))
+	if err != nil {
+		return err
+	}
+	if *out != "" {
+		return ioutil.WriteFile(*out, data, 0644)
+	}
+	_, err = c.stdout.Write(data)
+
+	return err
+}
+
+func (c *command) wikiAttachPut(args []string) error {
+	flags := flag.NewFlagSet("wiki attach put", flag.ContinueOnError)
+	description := flags.String("description", "", "description of the attachment")
+	name := flags.String("name", "", "filename of the attachment. the base name of the file if not given")
+	if err := parseFlags(flags, args, 2, 2); err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	if *name == "" {
+		*name = filepath.Base(flags.Arg(1))
+	}
+	filename, err := c.client.Wiki.PutAttachmentEx(tracrpc.String(flags.Arg(0)), name, description, data, tracrpc.Bool(true))
+	if err != nil {
+		return err
+	}
+
+	return c.print(filename, func(w io.Writer) {
+		fmt.Fprintln(w, filename)
+	})
+}
+
+func (c *command) wikiAttachRemove(args []string) error {
+	flags := flag.NewFlagSet("wiki attach rm", flag.ContinueOnError)
+	if err := parseFlags(flags, args, 1, 1); err != nil {
+		return err
+	}
+
+	ok, err := c.client.Wiki.DeleteAttachment(tracrpc.String(flags.Arg(0)))
+	if err != nil {
+		return err
+	}
+
+	return c.print(ok, func(w io.Writer) {})
+}
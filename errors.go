@@ -0,0 +1,77 @@
+package tracrpc
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Sentinel errors which a Fault matches with errors.Is.
+var (
+	ErrNotFound         = errors.New("not found")
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrMethodNotFound   = errors.New("method not found")
+	ErrInvalidArgs      = errors.New("invalid arguments")
+)
+
+// Fault codes used by the Trac RPC plugin.
+const (
+	faultCodeGeneric          = 1
+	faultCodePermissionDenied = 403
+	faultCodeNotFound         = 404
+	faultCodeMethodNotFound   = -32601
+	faultCodeInvalidParams    = -32602
+)
+
+// invalidArgsRx matches the messages of Python TypeError raised for wrong arguments,
+// which the server returns with the generic fault code.
+var invalidArgsRx = regexp.MustCompile(`takes (exactly|at least|at most|no) .*arguments?|unexpected keyword argument|argument.* must be`)
+
+// StatusError represents the error of the HTTP response with non-2xx status code.
+type StatusError struct {
+	StatusCode int
+}
+
+// Error implements the error interface.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("request error: bad status code - %d", e.StatusCode)
+}
+
+// Fault represents the fault returned by the server.
+type Fault struct {
+	Code    int
+	Message string
+	Method  string
+}
+
+// newFault creates new Fault instance.
+func newFault(methodName string, code int, message string) *Fault {
+	return &Fault{
+		Code:    code,
+		Message: message,
+		Method:  methodName,
+	}
+}
+
+// Error implements the error interface.
+func (f *Fault) Error() string {
+	return fmt.Sprintf("%s: fault(%d): %s", f.Method, f.Code, f.Message)
+}
+
+// Is reports whether the fault matches the sentinel error.
+func (f *Fault) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return f.Code == faultCodeNotFound || strings.Contains(f.Message, "does not exist")
+	case ErrPermissionDenied:
+		return f.Code == faultCodePermissionDenied || strings.Contains(f.Message, "privileges are required")
+	case ErrMethodNotFound:
+		return f.Code == faultCodeMethodNotFound
+	case ErrInvalidArgs:
+		return f.Code == faultCodeInvalidParams ||
+			(f.Code == faultCodeGeneric && invalidArgsRx.MatchString(f.Message))
+	}
+
+	return false
+}
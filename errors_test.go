@@ -0,0 +1,66 @@
+package tracrpc
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFaultIs(t *testing.T) {
+	sentinels := []error{ErrNotFound, ErrPermissionDenied, ErrMethodNotFound, ErrInvalidArgs}
+	tests := []struct {
+		name     string
+		fault    *Fault
+		expected error
+	}{
+		{
+			name:     "NotFound",
+			fault:    newFault(wiki_get_page, 404, `Wiki page "shiga" does not exist`),
+			expected: ErrNotFound,
+		},
+		{
+			name:     "NotFoundByMessage",
+			fault:    newFault(ticket_get, 1, "Ticket 7 does not exist."),
+			expected: ErrNotFound,
+		},
+		{
+			name:     "PermissionDenied",
+			fault:    newFault(wiki_get_page, 403, "WIKI_VIEW privileges are required to perform this operation"),
+			expected: ErrPermissionDenied,
+		},
+		{
+			name:     "MethodNotFound",
+			fault:    newFault("wiki.getPages", -32601, `RPC method "wiki.getPages" not found`),
+			expected: ErrMethodNotFound,
+		},
+		{
+			name:     "InvalidParams",
+			fault:    newFault(wiki_get_page, -32602, "Invalid params"),
+			expected: ErrInvalidArgs,
+		},
+		{
+			name:     "InvalidArgsByMessage",
+			fault:    newFault(wiki_get_page, 1, "'getPage() takes at least 3 arguments (2 given)' while executing 'wiki.getPage()'"),
+			expected: ErrInvalidArgs,
+		},
+		{
+			name:     "Other",
+			fault:    newFault(wiki_put_page, 1, "'Page not modified' while executing 'wiki.putPage()'"),
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error = tt.fault
+			for _, sentinel := range sentinels {
+				if res := errors.Is(err, sentinel); res != (sentinel == tt.expected) {
+					t.Fatalf("unexpected result of errors.Is(%v, %v). expected=%v, got=%v", err, sentinel, !res, res)
+				}
+			}
+			var fault *Fault
+			if !errors.As(err, &fault) || fault != tt.fault {
+				t.Fatalf("errors.As failed. got=%v", fault)
+			}
+		})
+	}
+}
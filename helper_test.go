@@ -2,6 +2,7 @@ package tracrpc
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -15,28 +16,17 @@ func (c *RpcClientMock) Call(methodName string, args interface{}, reply interfac
 	return nil
 }
 
-type rpcClientWithExpectedMethodName struct {
-	rpcClient          RpcClient
-	expectedMethodName string
-	expectedArgs       interface{}
-}
-
-func (c *rpcClientWithExpectedMethodName) Call(methodName string, args interface{}, reply interface{}) error {
-	if methodName != c.expectedMethodName {
-		return fmt.Errorf("unexpected method name. expected=%s, got=%s", c.expectedMethodName, methodName)
-	}
-	if !reflect.DeepEqual(args, c.expectedArgs) {
-		return fmt.Errorf("unexpected args. expected=%v, got=%v", c.expectedArgs, args)
-	}
-
-	return c.rpcClient.Call(methodName, args, reply)
-}
-
-func newRpcClientWithExpectedValues(rpcClient RpcClient, expectedMethodName string, expectedArgs interface{}) RpcClient {
-	return &rpcClientWithExpectedMethodName{
-		rpcClient:          rpcClient,
-		expectedMethodName: expectedMethodName,
-		expectedArgs:       expectedArgs,
+// newExpectedValuesInterceptor creates Interceptor which checks the method name and args of calls.
+func newExpectedValuesInterceptor(expectedMethodName string, expectedArgs interface{}) Interceptor {
+	return func(ctx context.Context, call *Call, invoker Invoker) error {
+		if call.MethodName != expectedMethodName {
+			return fmt.Errorf("unexpected method name. expected=%s, got=%s", expectedMethodName, call.MethodName)
+		}
+		if !reflect.DeepEqual(call.Args, expectedArgs) {
+			return fmt.Errorf("unexpected args. expected=%v, got=%v", expectedArgs, call.Args)
+		}
+
+		return invoker(ctx, call)
 	}
 }
 
@@ -55,21 +45,7 @@ func NewTestClient(expectedMethodName string, expectedArgs interface{}, reply st
 				Body:       ioutil.NopCloser(bytes.NewBufferString(reply)),
 			}
 		}),
-	)
-	c.Search.rpc = newRpcClientWithExpectedValues(
-		c.Search.rpc,
-		expectedMethodName,
-		expectedArgs,
-	)
-	c.System.rpc = newRpcClientWithExpectedValues(
-		c.System.rpc,
-		expectedMethodName,
-		expectedArgs,
-	)
-	c.Wiki.rpc = newRpcClientWithExpectedValues(
-		c.Wiki.rpc,
-		expectedMethodName,
-		expectedArgs,
+		WithInterceptors(newExpectedValuesInterceptor(expectedMethodName, expectedArgs)),
 	)
 	return c
 }
@@ -0,0 +1,70 @@
+package tracrpc
+
+import (
+	"context"
+	"time"
+)
+
+// Call represents an API call passed to Interceptor.
+// Interceptors may modify Args before invoking the call.
+// Duration is set when the call is invoked by the underlying RpcClient.
+type Call struct {
+	MethodName string
+	Args       interface{}
+	Reply      interface{}
+	Duration   time.Duration
+}
+
+// Invoker invokes the call.
+type Invoker func(ctx context.Context, call *Call) error
+
+// Interceptor intercepts API calls of Client for logging, metrics, retries, and so on.
+// It calls invoker to proceed the call, and may call it more than once or not at all.
+// NOTE: The calls batched by SystemService.Multicall are intercepted as a single system.multicall.
+type Interceptor func(ctx context.Context, call *Call, invoker Invoker) error
+
+// WithInterceptors adds the interceptors. The first one is the outermost.
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(o *clientOptions) {
+		o.interceptors = append(o.interceptors, interceptors...)
+	}
+}
+
+// interceptedRpcClient represents RpcClient whose calls are intercepted.
+type interceptedRpcClient struct {
+	invoker Invoker
+}
+
+// newInterceptedRpcClient creates new interceptedRpcClient instance which calls rpc through interceptors.
+func newInterceptedRpcClient(rpc RpcClient, interceptors []Interceptor) *interceptedRpcClient {
+	invoker := func(ctx context.Context, call *Call) error {
+		start := time.Now()
+		err := callContext(ctx, rpc, call.MethodName, call.Args, call.Reply)
+		call.Duration = time.Since(start)
+		return err
+	}
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+		invoker = func(ctx context.Context, call *Call) error {
+			return interceptor(ctx, call, next)
+		}
+	}
+
+	return &interceptedRpcClient{
+		invoker: invoker,
+	}
+}
+
+// Call calls the method through the interceptors.
+func (c *interceptedRpcClient) Call(methodName string, args interface{}, reply interface{}) error {
+	return c.CallContext(context.Background(), methodName, args, reply)
+}
+
+// CallContext calls the method through the interceptors with ctx.
+func (c *interceptedRpcClient) CallContext(ctx context.Context, methodName string, args interface{}, reply interface{}) error {
+	return c.invoker(ctx, &Call{
+		MethodName: methodName,
+		Args:       args,
+		Reply:      reply,
+	})
+}
@@ -0,0 +1,94 @@
+package tracrpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestWithInterceptors(t *testing.T) {
+	reply := `<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><string>Shiga</string></value>
+</param>
+</params>
+</methodResponse>`
+	var order []string
+	var requested string
+	var observed Call
+	c, err := NewClient(
+		"http://example.com",
+		RoundTripFunc(func(req *http.Request) *http.Response {
+			body, _ := ioutil.ReadAll(req.Body)
+			requested = string(body)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(reply)),
+			}
+		}),
+		WithInterceptors(
+			func(ctx context.Context, call *Call, invoker Invoker) error {
+				order = append(order, "outer")
+				err := invoker(ctx, call)
+				observed = *call
+				return err
+			},
+			func(ctx context.Context, call *Call, invoker Invoker) error {
+				order = append(order, "inner")
+				// mutate the request
+				call.Args = packArgs(String("kyoto"))
+				return invoker(ctx, call)
+			},
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := c.Wiki.GetPage(String("shiga"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != "Shiga" {
+		t.Fatalf("unexpected result. expected=%v, got=%v", "Shiga", res)
+	}
+	if !reflect.DeepEqual(order, []string{"outer", "inner"}) {
+		t.Fatalf("unexpected order. got=%v", order)
+	}
+	if !bytes.Contains([]byte(requested), []byte("<string>kyoto</string>")) {
+		t.Fatalf("args are not mutated. got=%s", requested)
+	}
+	if observed.MethodName != wiki_get_page || observed.Duration <= 0 {
+		t.Fatalf("unexpected call. got=%+v", observed)
+	}
+	if reply, ok := observed.Reply.(*string); !ok || *reply != "Shiga" {
+		t.Fatalf("unexpected reply. got=%v", observed.Reply)
+	}
+}
+
+func TestWithInterceptorsShortCircuit(t *testing.T) {
+	errStop := errors.New("stop")
+	c, err := NewClient(
+		"http://example.com",
+		RoundTripFunc(func(req *http.Request) *http.Response {
+			t.Fatal("the call should not be sent")
+			return nil
+		}),
+		WithInterceptors(func(ctx context.Context, call *Call, invoker Invoker) error {
+			return errStop
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.System.ListMethods(); err != errStop {
+		t.Fatalf("unexpected error. expected=%v, got=%v", errStop, err)
+	}
+}
@@ -1,6 +1,14 @@
 package tracrpc
 
+import "context"
+
 // RpcClient represents a client for RPC.
 type RpcClient interface {
 	Call(methodName string, args interface{}, reply interface{}) error
 }
+
+// ContextRpcClient represents a client for RPC which supports context.Context.
+type ContextRpcClient interface {
+	RpcClient
+	CallContext(ctx context.Context, methodName string, args interface{}, reply interface{}) error
+}
@@ -0,0 +1,211 @@
+// Package diff produces unified diffs of texts.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// op represents the kind of an edit.
+type op int
+
+const (
+	opEqual op = iota
+	opDelete
+	opInsert
+)
+
+// edit represents an edit of a line. a and b are the indexes of the line in the old and the new text,
+// or the position of the insertion or the deletion in the other text.
+type edit struct {
+	op op
+	a  int
+	b  int
+}
+
+// Unified returns the unified diff of the texts with the headers and context lines of the context.
+// It returns an empty string if the texts are the same.
+func Unified(fromHeader, toHeader, from, to string, context int) string {
+	a := splitLines(from)
+	b := splitLines(to)
+	edits := diffLines(a, b)
+
+	out := &strings.Builder{}
+	for _, hunk := range hunks(edits, context) {
+		if out.Len() == 0 {
+			fmt.Fprintf(out, "--- %s\n+++ %s\n", fromHeader, toHeader)
+		}
+		writeHunk(out, hunk, a, b)
+	}
+
+	return out.String()
+}
+
+// splitLines splits the text into the lines, which end with "\n" except the last one.
+func splitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// diffLines returns the shortest edits from a to b by the Myers' algorithm.
+func diffLines(a, b []string) []edit {
+	n, m := len(a), len(b)
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	trace := [][]int{}
+
+search:
+	for d := 0; d <= max; d++ {
+		// only the diagonals which the backtracking refers to are kept.
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// backtrack the trace from the end.
+	edits := []edit{}
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		offset := d + 1
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, edit{op: opEqual, a: x, b: y})
+		}
+		if d > 0 {
+			if x == prevX {
+				edits = append(edits, edit{op: opInsert, a: prevX, b: prevY})
+			} else {
+				edits = append(edits, edit{op: opDelete, a: prevX, b: prevY})
+			}
+		}
+		x, y = prevX, prevY
+	}
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+
+	return edits
+}
+
+// hunks groups the changes with the context lines around them.
+func hunks(edits []edit, context int) [][]edit {
+	groups := [][]edit{}
+	for i := 0; i < len(edits); {
+		for i < len(edits) && edits[i].op == opEqual {
+			i++
+		}
+		if i == len(edits) {
+			break
+		}
+
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for {
+			for end < len(edits) && edits[end].op != opEqual {
+				end++
+			}
+			next := end
+			for next < len(edits) && edits[next].op == opEqual {
+				next++
+			}
+			if next < len(edits) && next-end <= 2*context {
+				// the next change is close enough to share the context lines.
+				end = next
+				continue
+			}
+			end += context
+			if end > next {
+				end = next
+			}
+			break
+		}
+		groups = append(groups, edits[start:end])
+		i = end
+	}
+
+	return groups
+}
+
+// writeHunk writes the hunk with the range header.
+func writeHunk(out *strings.Builder, hunk []edit, a, b []string) {
+	aLen, bLen := 0, 0
+	for _, e := range hunk {
+		if e.op != opInsert {
+			aLen++
+		}
+		if e.op != opDelete {
+			bLen++
+		}
+	}
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(hunk[0].a, aLen), hunkRange(hunk[0].b, bLen))
+
+	for _, e := range hunk {
+		switch e.op {
+		case opEqual:
+			writeLine(out, " ", a[e.a])
+		case opDelete:
+			writeLine(out, "-", a[e.a])
+		case opInsert:
+			writeLine(out, "+", b[e.b])
+		}
+	}
+}
+
+// hunkRange formats the range of the lines, which starts at the index.
+func hunkRange(start, length int) string {
+	switch length {
+	case 0:
+		// the empty range is denoted by the line before it.
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	}
+
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+// writeLine writes the line with the prefix, marking the missing newline at the end of the text.
+func writeLine(out *strings.Builder, prefix, line string) {
+	out.WriteString(prefix + line)
+	if !strings.HasSuffix(line, "\n") {
+		out.WriteString("\n\\ No newline at end of file\n")
+	}
+}
@@ -0,0 +1,59 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		expected string
+	}{
+		{"Same", "a\nb\n", "a\nb\n", ""},
+		{"Empty", "", "", ""},
+		{"Create", "", "a\nb\n", "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n"},
+		{"Remove", "a\n", "", "--- old\n+++ new\n@@ -1 +0,0 @@\n-a\n"},
+		{"Change", "a\nb\nc\n", "a\nB\nc\n", "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n"},
+		{"CRLF", "a\r\nb\r\n", "a\nb\n", ""},
+		{"NoNewline", "a\nb", "a\nc", "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n"},
+		{
+			"Context",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n",
+			"1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\nseventeen\n",
+			"--- old\n+++ new\n@@ -1,6 +1,6 @@\n 1\n 2\n-3\n+three\n 4\n 5\n 6\n@@ -14,3 +14,4 @@\n 14\n 15\n 16\n+seventeen\n",
+		},
+		{
+			"MergedHunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			"1\ntwo\n3\n4\n5\n6\n7\neight\n9\n",
+			"--- old\n+++ new\n@@ -1,9 +1,9 @@\n 1\n-2\n+two\n 3\n 4\n 5\n 6\n 7\n-8\n+eight\n 9\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Unified("old", "new", tt.from, tt.to, 3)
+			if got != tt.expected {
+				t.Fatalf("unexpected result. expected=%q, got=%q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDiffLinesShortest(t *testing.T) {
+	a := splitLines(strings.Repeat("a\nb\nc\n", 50))
+	b := splitLines(strings.Repeat("a\nc\nd\n", 50))
+	changes := 0
+	for _, e := range diffLines(a, b) {
+		if e.op != opEqual {
+			changes++
+		}
+	}
+	// the longest common subsequence is "a", "c" of each repetition.
+	if changes != 100 {
+		t.Fatalf("unexpected result. expected=%v, got=%v", 100, changes)
+	}
+}
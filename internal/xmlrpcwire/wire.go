@@ -0,0 +1,317 @@
+// Package xmlrpcwire encodes and decodes the XML-RPC messages on the server side,
+// which github.com/kolo/xmlrpc doesn't provide.
+package xmlrpcwire
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// iso8601 is the layout of dateTime.iso8601 used by Trac. Times are in UTC.
+const iso8601 = "20060102T15:04:05"
+
+// Fault represents the fault response.
+type Fault struct {
+	Code   int
+	String string
+}
+
+// Error implements the error interface.
+func (f *Fault) Error() string {
+	return fmt.Sprintf("fault(%d): %s", f.Code, f.String)
+}
+
+// value represents <value> element.
+// A value without type element is a string in the character data.
+type value struct {
+	CharData string   `xml:",chardata"`
+	Int      *string  `xml:"int"`
+	I4       *string  `xml:"i4"`
+	I8       *string  `xml:"i8"`
+	Boolean  *string  `xml:"boolean"`
+	String   *string  `xml:"string"`
+	Double   *string  `xml:"double"`
+	DateTime *string  `xml:"dateTime.iso8601"`
+	Base64   *string  `xml:"base64"`
+	Struct   *members `xml:"struct"`
+	Array    *values  `xml:"array"`
+}
+
+// members represents <struct> element.
+type members struct {
+	Members []member `xml:"member"`
+}
+
+// member represents <member> element.
+type member struct {
+	Name  string `xml:"name"`
+	Value value  `xml:"value"`
+}
+
+// values represents <array> element.
+type values struct {
+	Values []value `xml:"data>value"`
+}
+
+// methodCall represents <methodCall> element.
+type methodCall struct {
+	XMLName    xml.Name `xml:"methodCall"`
+	MethodName string   `xml:"methodName"`
+	Params     []value  `xml:"params>param>value"`
+}
+
+// methodResponse represents <methodResponse> element.
+type methodResponse struct {
+	XMLName xml.Name `xml:"methodResponse"`
+	Params  []value  `xml:"params>param>value,omitempty"`
+	Fault   *value   `xml:"fault>value,omitempty"`
+}
+
+// DecodeMethodCall decodes the method name and the params of methodCall.
+// The params are decoded into int64, bool, string, float64, time.Time, []byte,
+// map[string]interface{} and []interface{}.
+func DecodeMethodCall(data []byte) (string, []interface{}, error) {
+	var call methodCall
+	if err := xml.Unmarshal(data, &call); err != nil {
+		return "", nil, err
+	}
+	if call.MethodName == "" {
+		return "", nil, errors.New("methodName is missing")
+	}
+
+	params := make([]interface{}, 0, len(call.Params))
+	for _, param := range call.Params {
+		decoded, err := decodeValue(param)
+		if err != nil {
+			return "", nil, err
+		}
+		params = append(params, decoded)
+	}
+
+	return call.MethodName, params, nil
+}
+
+// DecodeMethodResponse decodes the value of methodResponse.
+// A fault is returned as *Fault error.
+func DecodeMethodResponse(data []byte) (interface{}, error) {
+	var resp methodResponse
+	if err := xml.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Fault != nil {
+		decoded, err := decodeValue(*resp.Fault)
+		if err != nil {
+			return nil, err
+		}
+		fault, _ := decoded.(map[string]interface{})
+		code, _ := fault["faultCode"].(int64)
+		message, _ := fault["faultString"].(string)
+		return nil, &Fault{Code: int(code), String: message}
+	}
+	if len(resp.Params) != 1 {
+		return nil, fmt.Errorf("unexpected number of params. got=%d", len(resp.Params))
+	}
+
+	return decodeValue(resp.Params[0])
+}
+
+// EncodeMethodCall encodes the method name and the params as methodCall.
+func EncodeMethodCall(methodName string, params ...interface{}) ([]byte, error) {
+	call := methodCall{
+		MethodName: methodName,
+		Params:     make([]value, 0, len(params)),
+	}
+	for _, param := range params {
+		encoded, err := encodeValue(reflect.ValueOf(param))
+		if err != nil {
+			return nil, err
+		}
+		call.Params = append(call.Params, encoded)
+	}
+
+	return marshal(call)
+}
+
+// EncodeMethodResponse encodes v as methodResponse.
+// If v is *Fault, it is encoded as the fault response.
+func EncodeMethodResponse(v interface{}) ([]byte, error) {
+	if fault, ok := v.(*Fault); ok {
+		return EncodeFault(fault.Code, fault.String), nil
+	}
+
+	encoded, err := encodeValue(reflect.ValueOf(v))
+	if err != nil {
+		return nil, err
+	}
+
+	return marshal(methodResponse{Params: []value{encoded}})
+}
+
+// EncodeFault encodes the fault response.
+func EncodeFault(code int, message string) []byte {
+	encoded, _ := encodeValue(reflect.ValueOf(map[string]interface{}{
+		"faultCode":   code,
+		"faultString": message,
+	}))
+	// never fails since the value consists of an int and a string.
+	data, _ := marshal(methodResponse{Fault: &encoded})
+
+	return data
+}
+
+// marshal marshals v with XML declaration.
+func marshal(v interface{}) ([]byte, error) {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+// decodeValue decodes v into Go value.
+func decodeValue(v value) (interface{}, error) {
+	switch {
+	case v.Int != nil:
+		return parseInt(*v.Int)
+	case v.I4 != nil:
+		return parseInt(*v.I4)
+	case v.I8 != nil:
+		return parseInt(*v.I8)
+	case v.Boolean != nil:
+		switch strings.TrimSpace(*v.Boolean) {
+		case "1":
+			return true, nil
+		case "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("invalid boolean. got=%s", *v.Boolean)
+	case v.String != nil:
+		return *v.String, nil
+	case v.Double != nil:
+		return strconv.ParseFloat(strings.TrimSpace(*v.Double), 64)
+	case v.DateTime != nil:
+		return parseTime(strings.TrimSpace(*v.DateTime))
+	case v.Base64 != nil:
+		return base64.StdEncoding.DecodeString(strings.TrimSpace(*v.Base64))
+	case v.Struct != nil:
+		decoded := make(map[string]interface{}, len(v.Struct.Members))
+		for _, m := range v.Struct.Members {
+			elem, err := decodeValue(m.Value)
+			if err != nil {
+				return nil, err
+			}
+			decoded[m.Name] = elem
+		}
+		return decoded, nil
+	case v.Array != nil:
+		decoded := make([]interface{}, 0, len(v.Array.Values))
+		for _, elem := range v.Array.Values {
+			decodedElem, err := decodeValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			decoded = append(decoded, decodedElem)
+		}
+		return decoded, nil
+	}
+
+	return v.CharData, nil
+}
+
+// parseInt parses the int element.
+func parseInt(s string) (int64, error) {
+	return strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+}
+
+// parseTime parses the dateTime.iso8601 element in the layouts which clients send.
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range []string{iso8601, "20060102T15:04:05Z07:00", "2006-01-02T15:04:05", "2006-01-02T15:04:05Z07:00"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid dateTime.iso8601. got=%s", s)
+}
+
+// encodeValue encodes Go value into value.
+// Maps must have string keys and are encoded in the order of the keys.
+func encodeValue(v reflect.Value) (value, error) {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return value{}, errors.New("nil cannot be encoded")
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return value{}, errors.New("nil cannot be encoded")
+	}
+
+	if t, ok := v.Interface().(time.Time); ok {
+		s := t.UTC().Format(iso8601)
+		return value{DateTime: &s}, nil
+	}
+	if b, ok := v.Interface().([]byte); ok {
+		s := base64.StdEncoding.EncodeToString(b)
+		return value{Base64: &s}, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		s := "0"
+		if v.Bool() {
+			s = "1"
+		}
+		return value{Boolean: &s}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s := strconv.FormatInt(v.Int(), 10)
+		return value{Int: &s}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s := strconv.FormatUint(v.Uint(), 10)
+		return value{Int: &s}, nil
+	case reflect.Float32, reflect.Float64:
+		s := strconv.FormatFloat(v.Float(), 'f', -1, 64)
+		return value{Double: &s}, nil
+	case reflect.String:
+		s := v.String()
+		return value{String: &s}, nil
+	case reflect.Slice, reflect.Array:
+		encoded := values{Values: make([]value, 0, v.Len())}
+		for i := 0; i < v.Len(); i++ {
+			elem, err := encodeValue(v.Index(i))
+			if err != nil {
+				return value{}, err
+			}
+			encoded.Values = append(encoded.Values, elem)
+		}
+		return value{Array: &encoded}, nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return value{}, fmt.Errorf("map key must be string. got=%v", v.Type().Key())
+		}
+		keys := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		encoded := members{Members: make([]member, 0, len(keys))}
+		for _, key := range keys {
+			elem, err := encodeValue(v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key())))
+			if err != nil {
+				return value{}, err
+			}
+			encoded.Members = append(encoded.Members, member{Name: key, Value: elem})
+		}
+		return value{Struct: &encoded}, nil
+	}
+
+	return value{}, fmt.Errorf("unsupported type %v", v.Type())
+}
@@ -0,0 +1,80 @@
+package xmlrpcwire
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kolo/xmlrpc"
+)
+
+func TestDecodeMethodCall(t *testing.T) {
+	when := time.Date(2014, time.December, 31, 12, 13, 24, 0, time.UTC)
+	data, err := xmlrpc.EncodeMethodCall("ticket.update",
+		1, "comment", map[string]interface{}{"owner": "ii", "cc": []string{"a", "b"}}, true, 1.5, when, xmlrpc.Base64("Yml3YWtv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	methodName, params, err := DecodeMethodCall(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if methodName != "ticket.update" {
+		t.Fatalf("unexpected method name. expected=%v, got=%v", "ticket.update", methodName)
+	}
+	expected := []interface{}{
+		int64(1),
+		"comment",
+		map[string]interface{}{"owner": "ii", "cc": []interface{}{"a", "b"}},
+		true,
+		1.5,
+		when,
+		[]byte("biwako"),
+	}
+	if !reflect.DeepEqual(params, expected) {
+		t.Fatalf("unexpected params. expected=%v, got=%v", expected, params)
+	}
+}
+
+func TestEncodeMethodResponse(t *testing.T) {
+	when := time.Date(2014, time.December, 31, 12, 13, 24, 0, time.UTC)
+	data, err := EncodeMethodResponse([]interface{}{1, "shiga", when, map[string]interface{}{"permanent": true}, []byte("biwako")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reply []interface{}
+	if err := xmlrpc.Response(data).Unmarshal(&reply); err != nil {
+		t.Fatal(err)
+	}
+	expected := []interface{}{int64(1), "shiga", when, map[string]interface{}{"permanent": true}, "Yml3YWtv"}
+	if !reflect.DeepEqual(reply, expected) {
+		t.Fatalf("unexpected reply. expected=%v, got=%v", expected, reply)
+	}
+
+	decoded, err := DecodeMethodResponse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, []interface{}{int64(1), "shiga", when, map[string]interface{}{"permanent": true}, []byte("biwako")}) {
+		t.Fatalf("unexpected decoded reply. got=%v", decoded)
+	}
+}
+
+func TestEncodeFault(t *testing.T) {
+	data := EncodeFault(404, `Wiki page "Kyoto" does not exist`)
+
+	err := xmlrpc.Response(data).Err()
+	expected := xmlrpc.FaultError{Code: 404, String: `Wiki page "Kyoto" does not exist`}
+	if err != expected {
+		t.Fatalf("unexpected error. expected=%v, got=%v", expected, err)
+	}
+
+	_, err = DecodeMethodResponse(data)
+	var fault *Fault
+	if !errors.As(err, &fault) || *fault != (Fault{Code: expected.Code, String: expected.String}) {
+		t.Fatalf("unexpected error. expected=%v, got=%v", expected, err)
+	}
+}
@@ -0,0 +1,335 @@
+package tracrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// jsonrpcTimeLayout is the layout of datetime in __jsonclass__.
+const jsonrpcTimeLayout = "2006-01-02T15:04:05"
+
+// jsonrpcClient represents RpcClient over JSON-RPC.
+type jsonrpcClient struct {
+	url        string
+	httpClient *http.Client
+	id         uint64
+}
+
+// jsonrpcRequest represents the request of JSON-RPC.
+type jsonrpcRequest struct {
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+	ID     uint64        `json:"id"`
+}
+
+// jsonrpcResponse represents the response of JSON-RPC.
+type jsonrpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *jsonrpcError   `json:"error"`
+	ID     uint64          `json:"id"`
+}
+
+// jsonrpcError represents the error of JSON-RPC.
+type jsonrpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Name    string `json:"name"`
+}
+
+// newJsonrpcClient creates new jsonrpcClient instance.
+// Cookies received from the server are sent back in the subsequent calls.
+func newJsonrpcClient(rawurl string, transport http.RoundTripper) (*jsonrpcClient, error) {
+	if _, err := url.Parse(rawurl); err != nil {
+		return nil, err
+	}
+	httpClient, err := newHTTPClient(transport)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jsonrpcClient{
+		url:        rawurl,
+		httpClient: httpClient,
+	}, nil
+}
+
+// Call calls the method without deadline.
+func (c *jsonrpcClient) Call(methodName string, args interface{}, reply interface{}) error {
+	return c.CallContext(context.Background(), methodName, args, reply)
+}
+
+// CallContext calls the method. The request is canceled when ctx is done.
+func (c *jsonrpcClient) CallContext(ctx context.Context, methodName string, args interface{}, reply interface{}) error {
+	params := []interface{}{}
+	if packed, ok := args.([]interface{}); ok {
+		for _, arg := range packed {
+			param, err := encodeJSONValue(reflect.ValueOf(arg))
+			if err != nil {
+				return fmt.Errorf("%s: %w", methodName, err)
+			}
+			params = append(params, param)
+		}
+	}
+	if methodName == system_multicall {
+		params = encodeJSONMulticall(params)
+	}
+	body, err := json.Marshal(jsonrpcRequest{
+		Method: methodName,
+		Params: params,
+		ID:     atomic.AddUint64(&c.id, 1),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	var jsonResp jsonrpcResponse
+	if err := json.Unmarshal(respBody, &jsonResp); err != nil {
+		return err
+	}
+	if jsonResp.Error != nil {
+		return newFault(methodName, jsonResp.Error.Code, jsonResp.Error.Message)
+	}
+	if reply == nil {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(jsonResp.Result))
+	dec.UseNumber()
+	var result interface{}
+	if err := dec.Decode(&result); err != nil {
+		return err
+	}
+	value, err := decodeJSONValue(result)
+	if err != nil {
+		return fmt.Errorf("%s: %w", methodName, err)
+	}
+	if methodName == system_multicall {
+		value = decodeJSONMulticall(value)
+	}
+
+	return assign(reply, value)
+}
+
+// encodeJSONValue converts the arg into the value for JSON-RPC in the same manner as github.com/kolo/xmlrpc encodes.
+// time.Time and base64String are converted into __jsonclass__.
+func encodeJSONValue(val reflect.Value) (interface{}, error) {
+	if val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil, nil
+		}
+		return encodeJSONValue(val.Elem())
+	}
+
+	switch v := val.Interface().(type) {
+	case time.Time:
+		return jsonClass("datetime", v.Format(jsonrpcTimeLayout)), nil
+	case base64String:
+		return jsonClass("binary", string(v)), nil
+	}
+
+	switch val.Kind() {
+	case reflect.Struct:
+		members := map[string]interface{}{}
+		for i := 0; i < val.NumField(); i++ {
+			field := val.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			name := field.Tag.Get("xmlrpc")
+			omitEmpty := strings.HasSuffix(name, ",omitempty")
+			name = strings.TrimSuffix(name, ",omitempty")
+			if name == "" {
+				name = field.Name
+			}
+			if omitEmpty && val.Field(i).IsZero() {
+				continue
+			}
+			member, err := encodeJSONValue(val.Field(i))
+			if err != nil {
+				return nil, err
+			}
+			members[name] = member
+		}
+		return members, nil
+	case reflect.Map:
+		if val.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("only maps with string keys are supported. got=%s", val.Type())
+		}
+		members := make(map[string]interface{}, val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			member, err := encodeJSONValue(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			members[iter.Key().String()] = member
+		}
+		return members, nil
+	case reflect.Slice, reflect.Array:
+		elems := make([]interface{}, 0, val.Len())
+		for i := 0; i < val.Len(); i++ {
+			elem, err := encodeJSONValue(val.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, elem)
+		}
+		return elems, nil
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return val.Interface(), nil
+	}
+
+	return nil, fmt.Errorf("unsupported type %s", val.Type())
+}
+
+// decodeJSONValue converts the value decoded from JSON into the same form as github.com/kolo/xmlrpc decodes.
+// Integers become int64, datetime becomes time.Time and binary becomes Base64-Encoded string.
+func decodeJSONValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		return v.Float64()
+	case []interface{}:
+		elems := make([]interface{}, 0, len(v))
+		for _, elem := range v {
+			decoded, err := decodeJSONValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, decoded)
+		}
+		return elems, nil
+	case map[string]interface{}:
+		if class, ok := v["__jsonclass__"]; ok {
+			return decodeJSONClass(class)
+		}
+		members := make(map[string]interface{}, len(v))
+		for name, member := range v {
+			decoded, err := decodeJSONValue(member)
+			if err != nil {
+				return nil, err
+			}
+			members[name] = decoded
+		}
+		return members, nil
+	}
+
+	return value, nil
+}
+
+// decodeJSONClass decodes the value of __jsonclass__ of the form [class, value].
+func decodeJSONClass(class interface{}) (interface{}, error) {
+	elem, ok := class.([]interface{})
+	if !ok || len(elem) != 2 {
+		return nil, fmt.Errorf("unexpected __jsonclass__ form. got=%v", class)
+	}
+	value, ok := elem[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected __jsonclass__ value. got=%v", elem[1])
+	}
+
+	switch elem[0] {
+	case "datetime":
+		return time.Parse(jsonrpcTimeLayout, value)
+	case "binary":
+		return strings.NewReplacer("\n", "", "\r", "").Replace(value), nil
+	}
+
+	return nil, fmt.Errorf("unsupported __jsonclass__ %v", elem[0])
+}
+
+// encodeJSONMulticall converts the params of system.multicall into the form of JSON-RPC,
+// whose signatures have "method" instead of "methodName" and are not wrapped in an array.
+func encodeJSONMulticall(params []interface{}) []interface{} {
+	if len(params) != 1 {
+		return params
+	}
+	signatures, ok := params[0].([]interface{})
+	if !ok {
+		return params
+	}
+
+	for _, signature := range signatures {
+		if members, ok := signature.(map[string]interface{}); ok {
+			members["method"] = members["methodName"]
+			delete(members, "methodName")
+		}
+	}
+
+	return signatures
+}
+
+// decodeJSONMulticall converts the result of system.multicall of JSON-RPC, which is the list of responses,
+// into the form of XML-RPC, which is the list of either the array containing the result or the fault struct.
+func decodeJSONMulticall(value interface{}) interface{} {
+	responses, ok := value.([]interface{})
+	if !ok {
+		return value
+	}
+
+	results := make([]interface{}, 0, len(responses))
+	for _, response := range responses {
+		members, ok := response.(map[string]interface{})
+		if !ok {
+			results = append(results, response)
+			continue
+		}
+		if rpcErr, ok := members["error"].(map[string]interface{}); ok {
+			results = append(results, map[string]interface{}{
+				"faultCode":   rpcErr["code"],
+				"faultString": rpcErr["message"],
+			})
+			continue
+		}
+		results = append(results, []interface{}{members["result"]})
+	}
+
+	return results
+}
+
+// jsonClass returns the __jsonclass__ object.
+func jsonClass(class string, value string) map[string]interface{} {
+	return map[string]interface{}{
+		"__jsonclass__": []interface{}{class, value},
+	}
+}
@@ -0,0 +1,166 @@
+package tracrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newJsonrpcTestClient creates Client over JSON-RPC whose server checks the request and returns the reply.
+func newJsonrpcTestClient(t *testing.T, expectedMethodName string, expectedParams string, reply string) *Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var req struct {
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("invalid request. got=%s", body)
+		}
+		if req.Method != expectedMethodName {
+			t.Errorf("unexpected method name. expected=%s, got=%s", expectedMethodName, req.Method)
+		}
+		if string(req.Params) != expectedParams {
+			t.Errorf("unexpected params. expected=%s, got=%s", expectedParams, req.Params)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(reply))
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := NewClient(server.URL, nil, WithProtocol(ProtocolJSONRPC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func TestJsonrpcGetPageInfo(t *testing.T) {
+	c := newJsonrpcTestClient(t, wiki_get_page_info, `["shiga",1]`, `{"id": 1, "error": null, "result": {
+"comment": "ouch",
+"lastModified": {"__jsonclass__": ["datetime", "1860-03-24T00:00:00"]},
+"version": 1,
+"name": "sakuradamon",
+"author": "n_ii"}}`)
+	expected := PageInfo{
+		Name:         "sakuradamon",
+		LastModified: time.Date(1860, time.March, 24, 0, 0, 0, 0, time.UTC),
+		Author:       "n_ii",
+		Version:      1,
+		Comment:      "ouch",
+	}
+
+	res, err := c.Wiki.GetPageInfo(String("shiga"), Int(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != expected {
+		t.Fatalf("unexpected result. expected=%v, got=%v", expected, res)
+	}
+}
+
+func TestJsonrpcGetAttachment(t *testing.T) {
+	c := newJsonrpcTestClient(t, wiki_get_attachment, `["WikiTest/Otsu.txt"]`, `{"id": 1, "error": null, "result":
+{"__jsonclass__": ["binary", "5ruL\n6LOA\n"]}}`)
+	expected := []byte("滋賀")
+
+	res, err := c.Wiki.GetAttachment(String("WikiTest/Otsu.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Fatalf("unexpected result. expected=%v, got=%v", expected, res)
+	}
+}
+
+func TestJsonrpcPutAttachmentEx(t *testing.T) {
+	c := newJsonrpcTestClient(t, wiki_put_attachment_ex, `["WikiTest","Shiga.txt","desc",{"__jsonclass__":["binary","5ruL6LOA"]},true]`, `{"id": 1, "error": null, "result": "Shiga.txt"}`)
+
+	res, err := c.Wiki.PutAttachmentEx(String("WikiTest"), String("Shiga.txt"), String("desc"), []byte("滋賀"), Bool(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != "Shiga.txt" {
+		t.Fatalf("unexpected result. expected=%v, got=%v", "Shiga.txt", res)
+	}
+}
+
+func TestJsonrpcTicketCreate(t *testing.T) {
+	c := newJsonrpcTestClient(t, ticket_create, `["sakuradamon","ouch",{"owner":"n_ii"},false,{"__jsonclass__":["datetime","1860-03-24T00:00:00"]}]`, `{"id": 1, "error": null, "result": 7}`)
+
+	res, err := c.Ticket.Create(String("sakuradamon"), String("ouch"), map[string]interface{}{"owner": "n_ii"}, nil, Time(time.Date(1860, time.March, 24, 0, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != 7 {
+		t.Fatalf("unexpected result. expected=%v, got=%v", 7, res)
+	}
+}
+
+func TestJsonrpcTicketGet(t *testing.T) {
+	c := newJsonrpcTestClient(t, ticket_get, `[7]`, `{"id": 1, "error": null, "result": [7,
+{"__jsonclass__": ["datetime", "1860-03-24T00:00:00"]},
+{"__jsonclass__": ["datetime", "1867-11-09T00:00:00"]},
+{"summary": "sakuradamon", "status": "new", "_ts": "1234567890"}]}`)
+
+	res, err := c.Ticket.Get(Int(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, ticketExpected) {
+		t.Fatalf("unexpected result. expected=%v, got=%v", ticketExpected, res)
+	}
+}
+
+func TestJsonrpcPutPage(t *testing.T) {
+	c := newJsonrpcTestClient(t, wiki_put_page, `["shiga","content",{"author":"murasakishikibu","comment":null,"readonly":true}]`, `{"id": 1, "error": null, "result": true}`)
+
+	res, err := c.Wiki.PutPage(String("shiga"), String("content"), PutPageAttributes{Readonly: Bool(true), Author: String("murasakishikibu")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res {
+		t.Fatalf("unexpected result. expected=%v, got=%v", true, res)
+	}
+}
+
+func TestJsonrpcFault(t *testing.T) {
+	c := newJsonrpcTestClient(t, wiki_get_page, `["shiga"]`, `{"id": 1, "result": null,
+"error": {"message": "Wiki page \"shiga\" does not exist", "code": 404, "name": "JSONRPCError"}}`)
+
+	_, err := c.Wiki.GetPage(String("shiga"), nil)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("unexpected error. expected=%v, got=%v", ErrNotFound, err)
+	}
+}
+
+func TestJsonrpcMulticall(t *testing.T) {
+	c := newJsonrpcTestClient(t, system_multicall, `[{"method":"wiki.getPage","params":["shiga"]},{"method":"wiki.getPage","params":["kyoto"]}]`, `{"id": 1, "error": null, "result": [
+{"id": 1, "error": null, "result": "Shiga"},
+{"id": 1, "result": null, "error": {"message": "Wiki page \"kyoto\" does not exist", "code": 404, "name": "JSONRPCError"}}]}`)
+	expected := []MulticallResult{
+		{Value: "Shiga"},
+		{Value: "", Err: &Fault{Code: 404, Message: `Wiki page "kyoto" does not exist`, Method: wiki_get_page}},
+	}
+
+	res, err := c.System.Multicall(
+		func(c *Client) (interface{}, error) {
+			return c.Wiki.GetPage(String("shiga"), nil)
+		},
+		func(c *Client) (interface{}, error) {
+			return c.Wiki.GetPage(String("kyoto"), nil)
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Fatalf("unexpected result. expected=%v, got=%v", expected, res)
+	}
+}
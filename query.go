@@ -0,0 +1,166 @@
+package tracrpc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// QueryOperator represents the operator of a ticket query condition.
+type QueryOperator string
+
+const (
+	QueryIs            QueryOperator = "="
+	QueryIsNot         QueryOperator = "!="
+	QueryContains      QueryOperator = "~="
+	QueryNotContains   QueryOperator = "!~="
+	QueryStartsWith    QueryOperator = "^="
+	QueryNotStartsWith QueryOperator = "!^="
+	QueryEndsWith      QueryOperator = "$="
+	QueryNotEndsWith   QueryOperator = "!$="
+)
+
+// queryEscaper escapes the characters which have special meaning in the query language.
+var queryEscaper = strings.NewReplacer("&", `\&`, "|", `\|`)
+
+// queryModes are the characters which the query parser takes as the operator at the start of a value.
+// They cannot be escaped.
+const queryModes = "~^$"
+
+// QueryUser is the value substituted with the name of the user calling the query by the server.
+const QueryUser = "$USER"
+
+// TicketQuery builds the query string of ticket.query.
+// The zero value is an empty query.
+type TicketQuery struct {
+	conditions []string
+	order      string
+	desc       bool
+	max        *int
+	page       *int
+	err        error
+}
+
+// NewTicketQuery creates new TicketQuery instance.
+func NewTicketQuery() *TicketQuery {
+	return &TicketQuery{}
+}
+
+// Where adds the condition on the field. Multiple values are OR-ed.
+// "&" and "|" in the values are escaped. The query parser takes a leading "!", "~", "^" or "$" of the value
+// as the operator, which cannot be escaped, so such values of QueryIs and the values of QueryIsNot
+// starting with "~", "^" or "$" are rejected, and the error is reported by Err. QueryUser is allowed as it is.
+// The values ending with "\" are rejected as well, since the backslash would escape the following separator.
+func (q *TicketQuery) Where(field string, op QueryOperator, values ...string) *TicketQuery {
+	escaped := make([]string, 0, len(values))
+	for _, value := range values {
+		if q.err == nil {
+			q.err = queryValueError(field, op, value)
+		}
+		escaped = append(escaped, queryEscaper.Replace(value))
+	}
+	q.conditions = append(q.conditions, field+string(op)+strings.Join(escaped, "|"))
+
+	return q
+}
+
+// Is adds the condition "field=values".
+func (q *TicketQuery) Is(field string, values ...string) *TicketQuery {
+	return q.Where(field, QueryIs, values...)
+}
+
+// IsNot adds the condition "field!=values".
+func (q *TicketQuery) IsNot(field string, values ...string) *TicketQuery {
+	return q.Where(field, QueryIsNot, values...)
+}
+
+// Contains adds the condition "field~=values".
+func (q *TicketQuery) Contains(field string, values ...string) *TicketQuery {
+	return q.Where(field, QueryContains, values...)
+}
+
+// StartsWith adds the condition "field^=values".
+func (q *TicketQuery) StartsWith(field string, values ...string) *TicketQuery {
+	return q.Where(field, QueryStartsWith, values...)
+}
+
+// EndsWith adds the condition "field$=values".
+func (q *TicketQuery) EndsWith(field string, values ...string) *TicketQuery {
+	return q.Where(field, QueryEndsWith, values...)
+}
+
+// OrderBy sets the field to sort the result by.
+func (q *TicketQuery) OrderBy(field string, desc bool) *TicketQuery {
+	q.order = field
+	q.desc = desc
+
+	return q
+}
+
+// Max sets the maximum number of results. 0 turns off paging.
+func (q *TicketQuery) Max(max int) *TicketQuery {
+	q.max = &max
+
+	return q
+}
+
+// Page sets the page of the results.
+func (q *TicketQuery) Page(page int) *TicketQuery {
+	q.page = &page
+
+	return q
+}
+
+// Err returns the error of the first condition which cannot be expressed in the query string, if any.
+func (q *TicketQuery) Err() error {
+	return q.err
+}
+
+// String returns the query string.
+func (q *TicketQuery) String() string {
+	parts := make([]string, 0, len(q.conditions)+4)
+	parts = append(parts, q.conditions...)
+	if q.order != "" {
+		parts = append(parts, "order="+q.order)
+		if q.desc {
+			parts = append(parts, "desc=1")
+		}
+	}
+	if q.max != nil {
+		parts = append(parts, "max="+strconv.Itoa(*q.max))
+	}
+	if q.page != nil {
+		parts = append(parts, "page="+strconv.Itoa(*q.page))
+	}
+
+	return strings.Join(parts, "&")
+}
+
+// queryValueError returns the error if the value cannot be expressed in the query string.
+func queryValueError(field string, op QueryOperator, value string) error {
+	switch {
+	case isAmbiguousQueryValue(op, value):
+		return fmt.Errorf("value %q of %s cannot be queried with %q, since it starts with an operator", value, field, op)
+	case strings.HasSuffix(value, `\`):
+		return fmt.Errorf("value %q of %s cannot be queried, since it ends with a backslash", value, field)
+	}
+
+	return nil
+}
+
+// isAmbiguousQueryValue reports whether the query parser takes the start of the value as the operator.
+// The value following "~", "^" or "$" of the operator is taken literally.
+func isAmbiguousQueryValue(op QueryOperator, value string) bool {
+	if value == "" || value == QueryUser {
+		return false
+	}
+	mode := strings.TrimSuffix(string(op), "=")
+	switch mode {
+	case "":
+		return value[0] == '!' || strings.IndexByte(queryModes, value[0]) >= 0
+	case "!":
+		return strings.IndexByte(queryModes, value[0]) >= 0
+	}
+
+	return false
+}
@@ -0,0 +1,102 @@
+package tracrpc
+
+import "testing"
+
+func TestTicketQueryString(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    *TicketQuery
+		expected string
+	}{
+		{
+			name:     "Empty",
+			query:    NewTicketQuery(),
+			expected: "",
+		},
+		{
+			name:     "Operators",
+			query:    NewTicketQuery().Is("status", "new").IsNot("owner", "n_ii").Contains("summary", "mon").StartsWith("keywords", "edo").EndsWith("component", "gate"),
+			expected: "status=new&owner!=n_ii&summary~=mon&keywords^=edo&component$=gate",
+		},
+		{
+			name:     "OrValues",
+			query:    NewTicketQuery().Is("status", "new", "assigned", "reopened"),
+			expected: "status=new|assigned|reopened",
+		},
+		{
+			name:     "Escape",
+			query:    NewTicketQuery().Contains("summary", "a&b", "c|d"),
+			expected: `summary~=a\&b|c\|d`,
+		},
+		{
+			name:     "User",
+			query:    NewTicketQuery().Is("owner", QueryUser).IsNot("status", "closed"),
+			expected: "owner=$USER&status!=closed",
+		},
+		{
+			name:     "Where",
+			query:    NewTicketQuery().Where("summary", QueryNotContains, "sakuradamon"),
+			expected: "summary!~=sakuradamon",
+		},
+		{
+			name:     "OrderAndPaging",
+			query:    NewTicketQuery().Is("milestone", "edo").OrderBy("priority", true).Max(25).Page(2),
+			expected: "milestone=edo&order=priority&desc=1&max=25&page=2",
+		},
+		{
+			name:     "Unlimited",
+			query:    NewTicketQuery().OrderBy("id", false).Max(0),
+			expected: "order=id&max=0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if res := tt.query.String(); res != tt.expected {
+				t.Fatalf("unexpected result. expected=%v, got=%v", tt.expected, res)
+			}
+		})
+	}
+}
+
+func TestTicketQueryErr(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   *TicketQuery
+		wantErr bool
+	}{
+		{"IsNegation", NewTicketQuery().Is("owner", "!bob"), true},
+		{"IsContains", NewTicketQuery().Is("summary", "~x"), true},
+		{"IsStartsWith", NewTicketQuery().Is("summary", "ok", "^x"), true},
+		{"IsEndsWith", NewTicketQuery().Is("summary", "$x"), true},
+		{"IsNotContains", NewTicketQuery().IsNot("summary", "~x"), true},
+		{"IsNotNegation", NewTicketQuery().IsNot("owner", "!bob"), false},
+		{"ContainsLiteral", NewTicketQuery().Contains("summary", "~x", "!y"), false},
+		{"StartsWithLiteral", NewTicketQuery().StartsWith("summary", "^x"), false},
+		{"NotEndsWithLiteral", NewTicketQuery().Where("summary", QueryNotEndsWith, "$x"), false},
+		{"Inner", NewTicketQuery().Is("summary", "a!~^$"), false},
+		{"IsUser", NewTicketQuery().Is("owner", QueryUser), false},
+		{"IsNotUser", NewTicketQuery().IsNot("reporter", "$USER"), false},
+		{"IsUserPrefix", NewTicketQuery().Is("owner", "$USERS"), true},
+		{"TrailingBackslashCondition", NewTicketQuery().Is("summary", `C:\`).Is("status", "new"), true},
+		{"TrailingBackslashValues", NewTicketQuery().Is("summary", `a\`, "b"), true},
+		{"TrailingBackslashContains", NewTicketQuery().Contains("summary", `\`), true},
+		{"InnerBackslash", NewTicketQuery().Is("summary", `C:\Windows`), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.query.Err()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error. expected=%v, got=%v", tt.wantErr, err)
+			}
+			if !tt.wantErr {
+				return
+			}
+			// the query is rejected before calling the server.
+			if _, qerr := (&TicketService{}).Query(tt.query); qerr != err {
+				t.Fatalf("unexpected error. expected=%v, got=%v", err, qerr)
+			}
+		})
+	}
+}
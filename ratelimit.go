@@ -0,0 +1,138 @@
+package tracrpc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimit represents the limits of the calls to the server.
+// Zero values mean no limit.
+type RateLimit struct {
+	// RequestsPerSecond is the sustained rate of the calls.
+	RequestsPerSecond float64
+	// Burst is the number of calls allowed at once above the rate. It is at least 1.
+	Burst int
+	// MaxInFlight is the maximum number of calls in progress at the same time.
+	MaxInFlight int
+}
+
+// WithRateLimit limits the calls of the client.
+// The limit is shared by all the services of the client and the copies made by WithContext.
+// A batch of system.multicall is counted as one call.
+// It is added to the interceptors in the order of options, so put it after WithRetry to limit the retries as well.
+func WithRateLimit(limit RateLimit) Option {
+	return WithInterceptors(newRateLimitInterceptor(limit))
+}
+
+// newRateLimitInterceptor creates Interceptor which waits for the limiter before calls.
+func newRateLimitInterceptor(limit RateLimit) Interceptor {
+	limiter := newRateLimiter(limit)
+
+	return func(ctx context.Context, call *Call, invoker Invoker) error {
+		release, err := limiter.acquire(ctx)
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		return invoker(ctx, call)
+	}
+}
+
+// rateLimiter represents a token bucket with a cap of the calls in progress.
+type rateLimiter struct {
+	rate     float64
+	burst    float64
+	inFlight chan struct{}
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter creates new rateLimiter instance whose bucket is full.
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	burst := limit.Burst
+	if burst < 1 {
+		burst = 1
+	}
+	var inFlight chan struct{}
+	if limit.MaxInFlight > 0 {
+		inFlight = make(chan struct{}, limit.MaxInFlight)
+	}
+
+	return &rateLimiter{
+		rate:     limit.RequestsPerSecond,
+		burst:    float64(burst),
+		inFlight: inFlight,
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// acquire waits until a call is allowed, and returns the function to call when the call finishes.
+// It returns ctx.Err() if ctx is done while waiting.
+func (l *rateLimiter) acquire(ctx context.Context) (func(), error) {
+	if err := l.wait(ctx); err != nil {
+		return nil, err
+	}
+	if l.inFlight == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.inFlight <- struct{}{}:
+		return func() { <-l.inFlight }, nil
+	case <-ctx.Done():
+		l.giveBack()
+		return nil, ctx.Err()
+	}
+}
+
+// wait takes a token from the bucket, waiting for it to be refilled if empty.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l.rate <= 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	// take the token in advance so that the waiting calls are served in order.
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		if err := ctx.Err(); err != nil {
+			l.giveBack()
+			return err
+		}
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.giveBack()
+		return ctx.Err()
+	}
+}
+
+// giveBack returns the token taken by wait to the bucket, when the call is canceled without being made.
+func (l *rateLimiter) giveBack() {
+	if l.rate <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	l.tokens++
+	l.mu.Unlock()
+}
@@ -0,0 +1,123 @@
+package tracrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newRateLimitTestClient creates Client whose server takes the delay to respond and counts the calls in progress.
+func newRateLimitTestClient(limit RateLimit, delay time.Duration) (*Client, func() int) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	c, _ := NewClient(
+		"http://example.com",
+		RoundTripFunc(func(_ *http.Request) *http.Response {
+			mu.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mu.Unlock()
+
+			time.Sleep(delay)
+
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			return newStringResponse(http.StatusOK, stringReply)
+		}),
+		WithRateLimit(limit),
+	)
+
+	return c, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return maxInFlight
+	}
+}
+
+func TestWithRateLimitMaxInFlight(t *testing.T) {
+	c, maxInFlight := newRateLimitTestClient(RateLimit{MaxInFlight: 2}, 10*time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Wiki.GetPage(String("shiga"), nil); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if res := maxInFlight(); res != 2 {
+		t.Fatalf("unexpected number of calls in progress. expected=%v, got=%v", 2, res)
+	}
+}
+
+func TestWithRateLimitRequestsPerSecond(t *testing.T) {
+	c, _ := newRateLimitTestClient(RateLimit{RequestsPerSecond: 100, Burst: 2}, 0)
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		// the limit is shared by the copies
+		client := c
+		if i%2 == 1 {
+			client = c.WithContext(context.Background())
+		}
+		if _, err := client.Wiki.GetPage(String("shiga"), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the first 2 calls are the burst, and the other 4 wait 10ms each.
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Fatalf("calls are not limited. elapsed=%v", elapsed)
+	}
+}
+
+func TestWithRateLimitContext(t *testing.T) {
+	c, _ := newRateLimitTestClient(RateLimit{RequestsPerSecond: 0.001}, 0)
+	if _, err := c.Wiki.GetPage(String("shiga"), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := c.WithContext(ctx).Wiki.GetPage(String("shiga"), nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error. expected=%v, got=%v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestWithRateLimitCanceledInFlight(t *testing.T) {
+	// the burst allows 2 calls, and the bucket is never refilled in the test.
+	c, _ := newRateLimitTestClient(RateLimit{RequestsPerSecond: 0.001, Burst: 2, MaxInFlight: 1}, 100*time.Millisecond)
+
+	done := make(chan error)
+	go func() {
+		_, err := c.Wiki.GetPage(String("shiga"), nil)
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	// the call canceled while waiting for the call in progress gives back the token.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.WithContext(ctx).Wiki.GetPage(String("shiga"), nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error. expected=%v, got=%v", context.DeadlineExceeded, err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := c.WithContext(ctx).Wiki.GetPage(String("shiga"), nil); err != nil {
+		t.Fatalf("unexpected error. expected the token to be given back, got=%v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package recorder
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// Cassette represents the recorded exchanges, which are saved as JSON.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction represents a recorded request and its response.
+type Interaction struct {
+	// Method is the RPC method name, or "HTTP <method> <path>" for the requests which are not XML-RPC calls.
+	Method string `json:"method"`
+	// Params is the canonical form of the redacted params, which is compared on replay.
+	Params string `json:"params"`
+	// Request is the redacted request body.
+	Request string `json:"request,omitempty"`
+	// Response is the redacted response.
+	Response Response `json:"response"`
+}
+
+// Response represents a recorded response.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// LoadCassette loads the cassette from the file.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, err
+	}
+
+	return &cassette, nil
+}
+
+// Save saves the cassette into the file, creating the directory if needed.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
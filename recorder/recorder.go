@@ -0,0 +1,275 @@
+// Package recorder provides http.RoundTripper which records the Trac XML-RPC exchanges
+// into a cassette file and replays them later, so that tests run without a live server:
+//
+//	rec, err := recorder.New("testdata/trac12.json", recorder.ModeAuto,
+//		recorder.WithReplacements("jdoe", "author1"))
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer rec.Stop()
+//	client, _ := tracrpc.NewClient(url, rec)
+//
+// The recorded calls are matched by the method name and the params.
+// Credentials in the headers, the cookie values and the "password" members are always redacted.
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/f-velka/tracrpc/internal/xmlrpcwire"
+)
+
+// Mode represents whether Recorder records or replays.
+type Mode int
+
+const (
+	// ModeReplay replays the cassette. Requests not in the cassette fail with ErrInteractionNotFound.
+	ModeReplay Mode = iota
+	// ModeRecord sends the requests to the server and records them into the cassette, overwriting it.
+	ModeRecord
+	// ModeAuto replays the cassette if it exists, otherwise records.
+	ModeAuto
+)
+
+// ErrInteractionNotFound is returned on replay when no recorded interaction matches the request.
+var ErrInteractionNotFound = errors.New("recorded interaction not found")
+
+// Option represents an option of New.
+type Option func(*Recorder)
+
+// WithTransport sets the transport used to record. The default is http.DefaultTransport.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(r *Recorder) {
+		r.transport = transport
+	}
+}
+
+// WithRedactedHeaders removes the headers from the cassette in addition to the ones carrying credentials.
+func WithRedactedHeaders(names ...string) Option {
+	return func(r *Recorder) {
+		for _, name := range names {
+			r.redactor.headers[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+}
+
+// WithRedactedMembers replaces the values of the struct members and the form fields with "REDACTED",
+// in addition to "password" and "__FORM_TOKEN".
+func WithRedactedMembers(names ...string) Option {
+	return func(r *Recorder) {
+		for _, name := range names {
+			r.redactor.members[name] = true
+		}
+	}
+}
+
+// WithReplacements replaces the strings in the cassette, e.g. author names.
+// It takes pairs of old and new strings, the same as strings.NewReplacer.
+// The requests are replaced in the same way before matching on replay.
+func WithReplacements(oldnew ...string) Option {
+	return func(r *Recorder) {
+		r.redactor.addReplacements(oldnew...)
+	}
+}
+
+// Recorder represents http.RoundTripper which records and replays the exchanges.
+type Recorder struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+	redactor  *redactor
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// New creates new Recorder with the cassette file.
+// In ModeReplay, and in ModeAuto if the file exists, the cassette is loaded.
+func New(path string, mode Mode, opts ...Option) (*Recorder, error) {
+	r := &Recorder{
+		path:      path,
+		mode:      mode,
+		transport: http.DefaultTransport,
+		redactor:  newRedactor(),
+		cassette:  &Cassette{Interactions: []*Interaction{}},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.mode == ModeAuto {
+		r.mode = ModeReplay
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			r.mode = ModeRecord
+		}
+	}
+	if r.mode == ModeReplay {
+		cassette, err := LoadCassette(path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = cassette
+		r.used = make([]bool, len(cassette.Interactions))
+	}
+
+	return r, nil
+}
+
+// Mode returns ModeRecord or ModeReplay the recorder works in.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Stop saves the cassette if recording.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.cassette.Save(r.path)
+}
+
+// RoundTrip records or replays the exchange.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	interaction := r.redactRequest(req, body)
+
+	if r.mode == ModeReplay {
+		return r.replay(req, interaction)
+	}
+
+	sent := req.Clone(req.Context())
+	sent.Body = ioutil.NopCloser(bytes.NewReader(body))
+	resp, err := r.transport.RoundTrip(sent)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	interaction.Response = Response{
+		StatusCode: resp.StatusCode,
+		Header:     r.redactor.header(resp.Header),
+		Body:       r.redactResponse(respBody),
+	}
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// replay finds the first unused interaction matching the request.
+// If all the matching interactions have been used, the last one is replayed again.
+func (r *Recorder) replay(req *http.Request, interaction *Interaction) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var found *Interaction
+	for i, recorded := range r.cassette.Interactions {
+		if recorded.Method != interaction.Method || recorded.Params != interaction.Params {
+			continue
+		}
+		found = recorded
+		if !r.used[i] {
+			r.used[i] = true
+			break
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%s %s: %w", interaction.Method, interaction.Params, ErrInteractionNotFound)
+	}
+
+	header := found.Response.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", found.Response.StatusCode, http.StatusText(found.Response.StatusCode)),
+		StatusCode:    found.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(found.Response.Body)),
+		ContentLength: int64(len(found.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// redactRequest creates the interaction of the redacted request.
+func (r *Recorder) redactRequest(req *http.Request, body []byte) *Interaction {
+	if methodName, params, err := xmlrpcwire.DecodeMethodCall(body); err == nil {
+		redactedParams := r.redactor.value(params).([]interface{})
+		canonical, _ := json.Marshal(redactedParams)
+		redactedBody, err := xmlrpcwire.EncodeMethodCall(methodName, redactedParams...)
+		if err != nil {
+			redactedBody = nil
+		}
+		return &Interaction{
+			Method:  methodName,
+			Params:  string(canonical),
+			Request: string(redactedBody),
+		}
+	}
+
+	redactedBody := r.redactor.replacer.Replace(string(body))
+	if strings.HasPrefix(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		if form, err := url.ParseQuery(string(body)); err == nil {
+			for name := range form {
+				if r.redactor.members[name] {
+					form.Set(name, redacted)
+				}
+			}
+			redactedBody = r.redactor.replacer.Replace(form.Encode())
+		}
+	}
+
+	return &Interaction{
+		Method:  "HTTP " + req.Method + " " + req.URL.Path,
+		Params:  redactedBody,
+		Request: redactedBody,
+	}
+}
+
+// redactResponse redacts the response body.
+// XML-RPC responses are re-encoded after redaction, and the others are replaced as text.
+func (r *Recorder) redactResponse(body []byte) string {
+	reply, err := xmlrpcwire.DecodeMethodResponse(body)
+	var fault *xmlrpcwire.Fault
+	switch {
+	case errors.As(err, &fault):
+		return string(xmlrpcwire.EncodeFault(fault.Code, r.redactor.replacer.Replace(fault.String)))
+	case err == nil:
+		if encoded, err := xmlrpcwire.EncodeMethodResponse(r.redactor.value(reply)); err == nil {
+			return string(encoded)
+		}
+	}
+
+	return r.redactor.replacer.Replace(string(body))
+}
@@ -0,0 +1,104 @@
+package recorder_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/f-velka/tracrpc"
+	"github.com/f-velka/tracrpc/recorder"
+	"github.com/f-velka/tracrpc/tractest"
+)
+
+// exercise calls the APIs and returns the results.
+func exercise(t *testing.T, c *tracrpc.Client) []interface{} {
+	t.Helper()
+	page1, err := c.Wiki.GetPage(tracrpc.String("Shiga"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Wiki.PutPage(tracrpc.String("Shiga"), tracrpc.String("biwako"), tracrpc.PutPageAttributes{}); err != nil {
+		t.Fatal(err)
+	}
+	// the same call returns the newer result.
+	page2, err := c.Wiki.GetPage(tracrpc.String("Shiga"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := c.Wiki.GetPageInfo(tracrpc.String("Shiga"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Wiki.GetPage(tracrpc.String("Kyoto"), nil)
+	if !errors.Is(err, tracrpc.ErrNotFound) {
+		t.Fatalf("unexpected error. expected=%v, got=%v", tracrpc.ErrNotFound, err)
+	}
+
+	return []interface{}{page1, page2, info}
+}
+
+func TestRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	srv := tractest.NewServer()
+	now := time.Date(2021, time.April, 1, 9, 0, 0, 0, time.UTC)
+	srv.Now = func() time.Time { return now }
+	srv.AddPage("Shiga", "ohmi", "jdoe", "")
+	rec, err := recorder.New(path, recorder.ModeAuto, recorder.WithReplacements("jdoe", "author1", "admin", "author2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Mode() != recorder.ModeRecord {
+		t.Fatalf("unexpected mode. expected=%v, got=%v", recorder.ModeRecord, rec.Mode())
+	}
+	c, err := tracrpc.NewClient(srv.URL+"/login/rpc", rec, tracrpc.WithBasicAuth("admin", "secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded := exercise(t, c)
+	srv.Close()
+	if err := rec.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"admin", "jdoe", "Authorization"} {
+		if strings.Contains(string(data), secret) {
+			t.Fatalf("cassette contains %s", secret)
+		}
+	}
+
+	rec, err = recorder.New(path, recorder.ModeAuto, recorder.WithReplacements("jdoe", "author1", "admin", "author2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Mode() != recorder.ModeReplay {
+		t.Fatalf("unexpected mode. expected=%v, got=%v", recorder.ModeReplay, rec.Mode())
+	}
+	c, err = tracrpc.NewClient(srv.URL+"/login/rpc", rec, tracrpc.WithBasicAuth("admin", "secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := exercise(t, c)
+	info := replayed[2].(tracrpc.PageInfo)
+	if info.Author != "author2" {
+		t.Fatalf("unexpected author. expected=%v, got=%v", "author2", info.Author)
+	}
+	info.Author = "admin"
+	replayed[2] = info
+	if !reflect.DeepEqual(replayed, recorded) {
+		t.Fatalf("unexpected results. expected=%v, got=%v", recorded, replayed)
+	}
+
+	_, err = c.Wiki.GetPage(tracrpc.String("Shiga"), tracrpc.Int(1))
+	if !errors.Is(err, recorder.ErrInteractionNotFound) {
+		t.Fatalf("unexpected error. expected=%v, got=%v", recorder.ErrInteractionNotFound, err)
+	}
+}
@@ -0,0 +1,108 @@
+package recorder
+
+import (
+	"net/http"
+	"strings"
+)
+
+// redacted replaces the values of the redacted struct members.
+const redacted = "REDACTED"
+
+// defaultRedactedHeaders are the headers which carry credentials.
+// The values of Set-Cookie are redacted instead so that the cookies still work on replay.
+var defaultRedactedHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization"}
+
+// defaultRedactedMembers are the struct members and the form fields which carry credentials.
+var defaultRedactedMembers = []string{"password", "__FORM_TOKEN"}
+
+// redactor removes the sensitive data from the exchanges.
+type redactor struct {
+	headers  map[string]bool
+	members  map[string]bool
+	replacer *strings.Replacer
+	pairs    []string
+}
+
+// newRedactor creates new redactor which removes the credential headers.
+func newRedactor() *redactor {
+	r := &redactor{
+		headers: make(map[string]bool),
+		members: make(map[string]bool),
+	}
+	for _, name := range defaultRedactedHeaders {
+		r.headers[http.CanonicalHeaderKey(name)] = true
+	}
+	for _, name := range defaultRedactedMembers {
+		r.members[name] = true
+	}
+	r.replacer = strings.NewReplacer()
+
+	return r
+}
+
+// addReplacements adds the pairs of old and new strings.
+func (r *redactor) addReplacements(oldnew ...string) {
+	r.pairs = append(r.pairs, oldnew...)
+	r.replacer = strings.NewReplacer(r.pairs...)
+}
+
+// header returns a copy of h without the redacted headers.
+func (r *redactor) header(h http.Header) http.Header {
+	redactedHeader := make(http.Header, len(h))
+	for name, values := range h {
+		name = http.CanonicalHeaderKey(name)
+		if r.headers[name] {
+			continue
+		}
+		for _, value := range values {
+			if name == "Set-Cookie" {
+				value = redactCookie(value)
+			}
+			redactedHeader.Add(name, r.replacer.Replace(value))
+		}
+	}
+
+	return redactedHeader
+}
+
+// value redacts the decoded XML-RPC value.
+// Strings are replaced, and the values of the redacted struct members are replaced with "REDACTED".
+func (r *redactor) value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return r.replacer.Replace(v)
+	case []interface{}:
+		redactedSlice := make([]interface{}, 0, len(v))
+		for _, elem := range v {
+			redactedSlice = append(redactedSlice, r.value(elem))
+		}
+		return redactedSlice
+	case map[string]interface{}:
+		redactedMap := make(map[string]interface{}, len(v))
+		for name, elem := range v {
+			if r.members[name] {
+				redactedMap[name] = redacted
+				continue
+			}
+			redactedMap[name] = r.value(elem)
+		}
+		return redactedMap
+	}
+
+	return v
+}
+
+// redactCookie replaces the value of Set-Cookie header, keeping the name and the attributes.
+func redactCookie(value string) string {
+	pair, attributes := value, ""
+	if i := strings.Index(value, ";"); i >= 0 {
+		pair, attributes = value[:i], value[i:]
+	}
+	i := strings.Index(pair, "=")
+	if i < 0 || strings.TrimSpace(pair[i+1:]) == "" {
+		// keep the cookies expired by the empty value.
+		return value
+	}
+
+	return pair[:i+1] + redacted + attributes
+}
@@ -0,0 +1,205 @@
+package tracrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultRetryMethods are the methods retried by default, which only read data from the server.
+var DefaultRetryMethods = []string{
+	search_get_search_filters,
+	search_perform_search,
+	system_list_methods,
+	system_method_help,
+	system_method_signature,
+	system_get_API_version,
+	ticket_query,
+	ticket_get_recent_changes,
+	ticket_get_available_actions,
+	ticket_get_actions,
+	ticket_get,
+	ticket_change_log,
+	ticket_list_attachments,
+	ticket_get_attachment,
+	ticket_get_ticket_fields,
+	ticket_component_get_all,
+	ticket_component_get,
+	ticket_milestone_get_all,
+	ticket_milestone_get,
+	ticket_version_get_all,
+	ticket_version_get,
+	ticket_priority + ticket_enum_get_all,
+	ticket_priority + ticket_enum_get,
+	ticket_resolution + ticket_enum_get_all,
+	ticket_resolution + ticket_enum_get,
+	ticket_severity + ticket_enum_get_all,
+	ticket_severity + ticket_enum_get,
+	ticket_type + ticket_enum_get_all,
+	ticket_type + ticket_enum_get,
+	ticket_status + ticket_enum_get_all,
+	wiki_get_recent_changes,
+	wiki_get_rpc_version_supported,
+	wiki_get_page,
+	wiki_get_page_version,
+	wiki_get_page_html,
+	wiki_get_page_html_version,
+	wiki_get_all_pages,
+	wiki_get_page_info,
+	wiki_get_page_info_version,
+	wiki_list_attachments,
+	wiki_get_attachment,
+	wiki_wiki_to_html,
+}
+
+// RetryPolicy represents the policy to retry failed calls with exponential backoff.
+// Zero values of MaxAttempts, InitialBackoff, MaxBackoff and Multiplier are replaced with the ones of DefaultRetryPolicy.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first call.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff is the upper limit of the delay.
+	MaxBackoff time.Duration
+	// Multiplier is the factor the delay is multiplied by after each retry.
+	Multiplier float64
+	// Jitter randomizes the delay by up to the fraction of it, in [0, 1].
+	Jitter float64
+	// Methods are the methods to retry. If nil, DefaultRetryMethods is used.
+	// Mutating methods such as wiki.putPage are retried only if they are listed here.
+	Methods []string
+	// IsRetryable reports whether the error should be retried.
+	// If nil, 502, 503 and 504 status and network errors are retried.
+	IsRetryable func(err error) bool
+}
+
+// DefaultRetryPolicy returns the default RetryPolicy.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// WithRetry retries failed calls according to the policy.
+// It is added to the interceptors in the order of options.
+func WithRetry(policy RetryPolicy) Option {
+	return WithInterceptors(newRetryInterceptor(policy))
+}
+
+// newRetryInterceptor creates Interceptor which retries calls according to the policy.
+func newRetryInterceptor(policy RetryPolicy) Interceptor {
+	defaults := DefaultRetryPolicy()
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = defaults.MaxAttempts
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = defaults.InitialBackoff
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = defaults.MaxBackoff
+	}
+	if policy.Multiplier <= 0 {
+		policy.Multiplier = defaults.Multiplier
+	}
+	if policy.Methods == nil {
+		policy.Methods = DefaultRetryMethods
+	}
+	if policy.IsRetryable == nil {
+		policy.IsRetryable = isRetryableError
+	}
+	methods := make(map[string]bool, len(policy.Methods))
+	for _, method := range policy.Methods {
+		methods[method] = true
+	}
+
+	return func(ctx context.Context, call *Call, invoker Invoker) error {
+		if !isRetryableCall(call, methods) {
+			return invoker(ctx, call)
+		}
+
+		var err error
+		for attempt := 0; attempt < policy.MaxAttempts; attempt++ {
+			if attempt > 0 {
+				timer := time.NewTimer(policy.backoff(attempt))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				case <-timer.C:
+				}
+			}
+
+			err = invoker(ctx, call)
+			if err == nil || ctx.Err() != nil || !policy.IsRetryable(err) {
+				return err
+			}
+		}
+
+		return err
+	}
+}
+
+// backoff returns the delay before the attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	if delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (rand.Float64()*2 - 1)
+	}
+
+	return time.Duration(delay)
+}
+
+// isRetryableCall checks the method of the call is in methods.
+// system.multicall is retryable if all the batched methods are.
+func isRetryableCall(call *Call, methods map[string]bool) bool {
+	if call.MethodName != system_multicall {
+		return methods[call.MethodName]
+	}
+
+	packed, ok := call.Args.([]interface{})
+	if !ok || len(packed) != 1 {
+		return false
+	}
+	params, ok := packed[0].(*[]multicallParam)
+	if !ok {
+		return false
+	}
+	for _, param := range *params {
+		if !methods[param.MethodName] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isRetryableError checks the error is temporary, which is either 502, 503, 504 status or a network error.
+func isRetryableError(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	return errors.Is(err, io.ErrUnexpectedEOF) || strings.Contains(err.Error(), "connection reset")
+}
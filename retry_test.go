@@ -0,0 +1,190 @@
+package tracrpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// newRetryTestClient creates Client whose server returns the statuses in order, then the reply with 200.
+func newRetryTestClient(statuses []int, reply string, policy RetryPolicy) (*Client, *int) {
+	requests := 0
+	c, _ := NewClient(
+		"http://example.com",
+		RoundTripFunc(func(_ *http.Request) *http.Response {
+			requests++
+			status := http.StatusOK
+			if requests <= len(statuses) {
+				status = statuses[requests-1]
+			}
+			return &http.Response{
+				StatusCode: status,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(reply)),
+			}
+		}),
+		WithRetry(policy),
+	)
+	return c, &requests
+}
+
+func TestRetry(t *testing.T) {
+	pagesReply := `<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><array><data>
+<value><string>WikiStart</string></value>
+</data></array></value>
+</param>
+</params>
+</methodResponse>`
+	policy := RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		Jitter:         0.5,
+	}
+	optIn := policy
+	optIn.Methods = append([]string{ticket_create}, DefaultRetryMethods...)
+	tests := []struct {
+		name             string
+		statuses         []int
+		reply            string
+		policy           RetryPolicy
+		call             func(c *Client) error
+		expectedRequests int
+		wantErr          bool
+	}{
+		{
+			name:     "Read",
+			statuses: []int{http.StatusBadGateway, http.StatusServiceUnavailable},
+			reply:    pagesReply,
+			policy:   policy,
+			call: func(c *Client) error {
+				_, err := c.Wiki.GetAllPages()
+				return err
+			},
+			expectedRequests: 3,
+			wantErr:          false,
+		},
+		{
+			name:     "GiveUp",
+			statuses: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+			reply:    pagesReply,
+			policy:   policy,
+			call: func(c *Client) error {
+				_, err := c.Wiki.GetAllPages()
+				return err
+			},
+			expectedRequests: 3,
+			wantErr:          true,
+		},
+		{
+			name:     "NotRetryableStatus",
+			statuses: []int{http.StatusInternalServerError},
+			reply:    pagesReply,
+			policy:   policy,
+			call: func(c *Client) error {
+				_, err := c.Wiki.GetAllPages()
+				return err
+			},
+			expectedRequests: 1,
+			wantErr:          true,
+		},
+		{
+			name:     "Mutating",
+			statuses: []int{http.StatusServiceUnavailable},
+			reply:    intReply,
+			policy:   policy,
+			call: func(c *Client) error {
+				_, err := c.Ticket.Create(String("sakuradamon"), String("ouch"), nil, nil, nil)
+				return err
+			},
+			expectedRequests: 1,
+			wantErr:          true,
+		},
+		{
+			name:     "MutatingOptIn",
+			statuses: []int{http.StatusServiceUnavailable},
+			reply:    intReply,
+			policy:   optIn,
+			call: func(c *Client) error {
+				_, err := c.Ticket.Create(String("sakuradamon"), String("ouch"), nil, nil, nil)
+				return err
+			},
+			expectedRequests: 2,
+			wantErr:          false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, requests := newRetryTestClient(tt.statuses, tt.reply, tt.policy)
+			err := tt.call(c)
+			if *requests != tt.expectedRequests {
+				t.Fatalf("unexpected number of requests. expected=%v, got=%v", tt.expectedRequests, *requests)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error. wantErr=%v, got=%v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRetryMulticall(t *testing.T) {
+	c, requests := newRetryTestClient([]int{http.StatusServiceUnavailable, http.StatusServiceUnavailable}, "", RetryPolicy{InitialBackoff: time.Millisecond})
+	c.System.Multicall(
+		func(c *Client) (interface{}, error) { return c.Wiki.GetPage(String("shiga"), nil) },
+		func(c *Client) (interface{}, error) { return c.Wiki.GetPageInfo(String("shiga"), nil) },
+	)
+	if *requests != 3 {
+		t.Fatalf("unexpected number of requests. expected=%v, got=%v", 3, *requests)
+	}
+
+	c, requests = newRetryTestClient([]int{http.StatusServiceUnavailable}, "", RetryPolicy{InitialBackoff: time.Millisecond})
+	c.System.Multicall(
+		func(c *Client) (interface{}, error) { return c.Wiki.GetPage(String("shiga"), nil) },
+		func(c *Client) (interface{}, error) { return c.Wiki.DeletePage(String("shiga"), nil) },
+	)
+	if *requests != 1 {
+		t.Fatalf("unexpected number of requests. expected=%v, got=%v", 1, *requests)
+	}
+}
+
+func TestRetryContext(t *testing.T) {
+	c, requests := newRetryTestClient([]int{http.StatusServiceUnavailable}, "", RetryPolicy{InitialBackoff: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := c.WithContext(ctx).Wiki.GetAllPages()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error. expected=%v, got=%v", context.DeadlineExceeded, err)
+	}
+	if *requests != 1 {
+		t.Fatalf("unexpected number of requests. expected=%v, got=%v", 1, *requests)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second}
+	for i, e := range expected {
+		if res := policy.backoff(i + 1); res != e {
+			t.Fatalf("unexpected backoff of attempt %d. expected=%v, got=%v", i+1, e, res)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if res := policy.backoff(1); res < 50*time.Millisecond || res > 150*time.Millisecond {
+			t.Fatalf("backoff out of jitter range. got=%v", res)
+		}
+	}
+}
@@ -2,6 +2,11 @@ package tracrpc
 
 import (
 	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/kolo/xmlrpc"
 )
 
 const (
@@ -12,11 +17,76 @@ const (
 	system_get_API_version  string = "system.getAPIVersion"
 )
 
+// errNestedMulticall is returned by Multicall called in MulticallFunc.
+var errNestedMulticall = errors.New("system.multicall cannot be nested in MulticallFunc")
+
+// errMulticallFinished is returned by the calls made after MulticallFunc has returned.
+var errMulticallFinished = errors.New("system.multicall: the call was made after MulticallFunc returned")
+
 // SystemService represents system API service.
 type SystemService struct {
 	rpc RpcClient
 }
 
+// MulticallFunc represents the function queued to system.multicall.
+// It calls the service methods of c as usual and returns the result of them.
+// The calls must finish before the function returns. The calls made after that,
+// e.g. by the goroutines started in the function, return an error.
+type MulticallFunc func(c *Client) (interface{}, error)
+
+// MulticallResult represents the result of MulticallFunc.
+type MulticallResult struct {
+	Value interface{}
+	Err   error
+}
+
+// multicallParam represents an element of the argument of system.multicall.
+type multicallParam struct {
+	MethodName string        `xmlrpc:"methodName"`
+	Params     []interface{} `xmlrpc:"params"`
+}
+
+// multicallCall represents an API call held by multicallRpcClient.
+// It is also used as the notification that the MulticallFunc has finished when done is nil.
+type multicallCall struct {
+	index      int
+	methodName string
+	args       interface{}
+	reply      interface{}
+	done       chan error
+}
+
+// multicallRpcClient represents RpcClient which hands over the calls to system.multicall.
+type multicallRpcClient struct {
+	index int
+	calls chan<- *multicallCall
+	// finished is closed when the MulticallFunc returns.
+	finished chan struct{}
+}
+
+// Call holds the call until system.multicall returns.
+func (c *multicallRpcClient) Call(methodName string, args interface{}, reply interface{}) error {
+	call := &multicallCall{
+		index:      c.index,
+		methodName: methodName,
+		args:       args,
+		reply:      reply,
+		done:       make(chan error, 1),
+	}
+	select {
+	case <-c.finished:
+		return errMulticallFinished
+	default:
+	}
+	select {
+	case c.calls <- call:
+	case <-c.finished:
+		return errMulticallFinished
+	}
+
+	return <-call.done
+}
+
 // newSystemService creates new SystemService instance.
 func newSystemService(rpc RpcClient) (*SystemService, error) {
 	if rpc == nil {
@@ -28,7 +98,130 @@ func newSystemService(rpc RpcClient) (*SystemService, error) {
 	}, nil
 }
 
-// TODO: system.multicall
+// Multicall calls system.multicall.
+// Each function is run against a Client whose API calls are batched,
+// so calls made by all functions at the same time are sent in one round trip.
+// A function may call APIs more than once; then subsequent calls are sent in the next round trip.
+// The results are returned in the order of funcs. A fault of each call is returned in MulticallResult.Err,
+// as well as a panic of the function. Multicall cannot be called by the functions.
+func (s *SystemService) Multicall(funcs ...MulticallFunc) ([]MulticallResult, error) {
+	if isMulticallRpcClient(s.rpc) {
+		return nil, errNestedMulticall
+	}
+
+	results := make([]MulticallResult, len(funcs))
+	calls := make(chan *multicallCall)
+	for i, f := range funcs {
+		rpc := &multicallRpcClient{index: i, calls: calls, finished: make(chan struct{})}
+		client, err := newClient(rpc)
+		if err != nil {
+			return nil, err
+		}
+		go func(i int, f MulticallFunc, client *Client) {
+			defer func() {
+				// the collector waits for every function to finish.
+				if r := recover(); r != nil {
+					results[i] = MulticallResult{Err: fmt.Errorf("%s: function %d panicked: %v", system_multicall, i, r)}
+				}
+				close(rpc.finished)
+				calls <- &multicallCall{index: i}
+			}()
+			value, err := f(client)
+			results[i] = MulticallResult{Value: value, Err: err}
+		}(i, f, client)
+	}
+
+	var roundTripErr error
+	for running := len(funcs); running > 0; {
+		// every running function either calls an API or finishes.
+		pending := make([]*multicallCall, 0, running)
+		for n := running; n > 0; n-- {
+			call := <-calls
+			if call.done == nil {
+				running--
+				continue
+			}
+			pending = append(pending, call)
+		}
+		if len(pending) == 0 {
+			continue
+		}
+
+		sort.Slice(pending, func(i, j int) bool { return pending[i].index < pending[j].index })
+		if roundTripErr == nil {
+			roundTripErr = s.multicall(pending)
+		}
+		if roundTripErr != nil {
+			for _, call := range pending {
+				call.done <- roundTripErr
+			}
+		}
+	}
+
+	return results, roundTripErr
+}
+
+// isMulticallRpcClient reports whether rpc is the client of MulticallFunc, which may be bound to a context.
+func isMulticallRpcClient(rpc RpcClient) bool {
+	if c, ok := rpc.(*contextRpcClient); ok {
+		rpc = c.rpc
+	}
+	_, ok := rpc.(*multicallRpcClient)
+
+	return ok
+}
+
+// multicall sends the calls in one system.multicall and delivers the results to them.
+func (s *SystemService) multicall(calls []*multicallCall) error {
+	params := make([]multicallParam, 0, len(calls))
+	for _, call := range calls {
+		param := multicallParam{
+			MethodName: call.methodName,
+			Params:     []interface{}{},
+		}
+		if packed, ok := call.args.([]interface{}); ok {
+			for _, arg := range packed {
+				param.Params = append(param.Params, reflect.ValueOf(arg).Elem().Interface())
+			}
+		}
+		params = append(params, param)
+	}
+
+	args := packArgs(&params)
+	var rawReply []interface{}
+	if err := s.rpc.Call(system_multicall, args, &rawReply); err != nil {
+		return err
+	}
+	if len(rawReply) != len(calls) {
+		return fmt.Errorf("%s: unexpected reply form. got=%v", system_multicall, rawReply)
+	}
+
+	for i, call := range calls {
+		call.done <- decodeMulticallReply(call.methodName, rawReply[i], call.reply)
+	}
+
+	return nil
+}
+
+// decodeMulticallReply decodes an element of the reply of system.multicall,
+// which is either the array containing the result or the fault struct.
+func decodeMulticallReply(methodName string, elem interface{}, reply interface{}) error {
+	switch elem := elem.(type) {
+	case []interface{}:
+		if len(elem) != 1 {
+			return fmt.Errorf("%s: unexpected reply form. got=%v", system_multicall, elem)
+		}
+		return assign(reply, elem[0])
+	case map[string]interface{}:
+		var fault xmlrpc.FaultError
+		if err := assign(&fault, elem); err != nil {
+			return err
+		}
+		return newFault(methodName, fault.Code, fault.String)
+	}
+
+	return fmt.Errorf("%s: unexpected reply form. got=%v", system_multicall, elem)
+}
 
 // ListMethods calls system.listMethods.
 func (s *SystemService) ListMethods() ([]string, error) {
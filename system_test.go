@@ -1,8 +1,13 @@
 package tracrpc
 
 import (
+	"context"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/f-velka/tracrpc/tractest"
 )
 
 func TestNewSystemService(t *testing.T) {
@@ -153,3 +158,201 @@ func TestGetAPIVersion(t *testing.T) {
 		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
 	}
 }
+
+func TestMulticall(t *testing.T) {
+	test := struct {
+		funcs    []MulticallFunc
+		params   []multicallParam
+		reply    string
+		expected []MulticallResult
+	}{
+		[]MulticallFunc{
+			func(c *Client) (interface{}, error) {
+				return c.Wiki.GetPageInfo(String("shiga"), Int(1))
+			},
+			func(c *Client) (interface{}, error) {
+				return c.Search.GetSearchFilters()
+			},
+			func(c *Client) (interface{}, error) {
+				return c.Wiki.GetPage(String("kyoto"), nil)
+			},
+		},
+		[]multicallParam{
+			{MethodName: wiki_get_page_info, Params: []interface{}{"shiga", 1}},
+			{MethodName: search_get_search_filters, Params: []interface{}{}},
+			{MethodName: wiki_get_page, Params: []interface{}{"kyoto"}},
+		},
+		`<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><array><data>
+<value><array><data>
+<value><struct>
+<member>
+<name>lastModified</name>
+<value><dateTime.iso8601>18600324T00:00:00</dateTime.iso8601></value>
+</member>
+<member>
+<name>version</name>
+<value><int>1</int></value>
+</member>
+<member>
+<name>name</name>
+<value><string>shiga</string></value>
+</member>
+</struct></value>
+</data></array></value>
+<value><array><data>
+<value><array><data>
+<value><array><data>
+<value><string>changeset</string></value>
+<value><string>Changesets</string></value>
+</data></array></value>
+</data></array></value>
+</data></array></value>
+<value><struct>
+<member>
+<name>faultCode</name>
+<value><int>404</int></value>
+</member>
+<member>
+<name>faultString</name>
+<value><string>Wiki page "kyoto" does not exist</string></value>
+</member>
+</struct></value>
+</data></array></value>
+</param>
+</params>
+</methodResponse>`,
+		[]MulticallResult{
+			{
+				Value: PageInfo{
+					Name:         "shiga",
+					LastModified: time.Date(1860, time.March, 24, 0, 0, 0, 0, time.UTC),
+					Version:      1,
+				},
+			},
+			{
+				Value: []SearchFilter{{Name: "changeset", Description: "Changesets"}},
+			},
+			{
+				Value: "",
+				Err:   &Fault{Code: 404, Message: `Wiki page "kyoto" does not exist`, Method: wiki_get_page},
+			},
+		},
+	}
+
+	c := NewTestClient(system_multicall, packArgs(&test.params), test.reply)
+	res, err := c.System.Multicall(test.funcs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, test.expected) {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestMulticallRoundTripError(t *testing.T) {
+	c := NewTestClient(system_multicall, nil, "")
+	res, err := c.System.Multicall(
+		func(c *Client) (interface{}, error) {
+			return c.System.ListMethods()
+		},
+	)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if len(res) != 1 || res[0].Err != err {
+		t.Fatalf("unexpected result. expected the round trip error, got=%v", res)
+	}
+}
+
+func TestMulticallPanicAndNested(t *testing.T) {
+	srv := tractest.NewServer()
+	defer srv.Close()
+	srv.AddPage("Shiga", "biwako", "ii", "")
+	c, err := NewClient(srv.URL+"/rpc", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var res []MulticallResult
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		res, err = c.System.Multicall(
+			func(c *Client) (interface{}, error) {
+				return c.Wiki.GetPage(String("Shiga"), nil)
+			},
+			func(c *Client) (interface{}, error) {
+				panic("ouch")
+			},
+			func(c *Client) (interface{}, error) {
+				return c.WithContext(context.Background()).System.Multicall(func(c *Client) (interface{}, error) {
+					return c.Wiki.GetPage(String("Shiga"), nil)
+				})
+			},
+		)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Multicall did not return")
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res[0].Value != "biwako" || res[0].Err != nil {
+		t.Fatalf("unexpected result. expected=%v, got=%v", "biwako", res[0])
+	}
+	if res[1].Err == nil || !strings.Contains(res[1].Err.Error(), "ouch") {
+		t.Fatalf("unexpected error. expected the panic, got=%v", res[1].Err)
+	}
+	if res[2].Err != errNestedMulticall {
+		t.Fatalf("unexpected error. expected=%v, got=%v", errNestedMulticall, res[2].Err)
+	}
+}
+
+func TestMulticallLateCall(t *testing.T) {
+	srv := tractest.NewServer()
+	defer srv.Close()
+	srv.AddPage("Shiga", "biwako", "ii", "")
+	c, err := NewClient(srv.URL+"/rpc", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := make(chan struct{})
+	late := make(chan error, 1)
+	res, err := c.System.Multicall(
+		func(c *Client) (interface{}, error) {
+			go func() {
+				<-start
+				_, err := c.Wiki.GetPage(String("Shiga"), nil)
+				late <- err
+			}()
+			return nil, nil
+		},
+		func(c *Client) (interface{}, error) {
+			return c.Wiki.GetPage(String("Shiga"), nil)
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res[1].Value != "biwako" {
+		t.Fatalf("unexpected result. expected=%v, got=%v", "biwako", res[1].Value)
+	}
+
+	close(start)
+	select {
+	case err := <-late:
+		if err != errMulticallFinished {
+			t.Fatalf("unexpected error. expected=%v, got=%v", errMulticallFinished, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the late call did not return")
+	}
+}
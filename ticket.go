@@ -1,30 +1,115 @@
 package tracrpc
 
 import (
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 )
 
 const (
-	ticket_query                string = "ticket.query"
-	ticket_getRecentChanges     string = "ticket.getRecentChanges"
-	ticket_getAvailableActionsy string = "ticket.getAvailableActions"
-	ticket_getActions           string = "ticket.getActions"
-	ticket_get                  string = "ticket.get"
-	ticket_create               string = "ticket.create"
-	ticket_update               string = "ticket.update"
-	ticket_delete               string = "ticket.delete"
-	ticket_change_log           string = "ticket.changeLog"
-	ticket_list_attachments     string = "ticket.listAttachments"
-	ticket_get_attachment       string = "ticket.getAttachment"
-	ticket_put_attachment       string = "ticket.putAttachment"
-	ticket_delete_attachment    string = "ticket.deleteAttachment"
-	ticket_get_ticket_fields    string = "ticket.getTicketFields"
+	ticket_query                 string = "ticket.query"
+	ticket_get_recent_changes    string = "ticket.getRecentChanges"
+	ticket_get_available_actions string = "ticket.getAvailableActions"
+	ticket_get_actions           string = "ticket.getActions"
+	ticket_get                   string = "ticket.get"
+	ticket_create                string = "ticket.create"
+	ticket_update                string = "ticket.update"
+	ticket_delete                string = "ticket.delete"
+	ticket_change_log            string = "ticket.changeLog"
+	ticket_list_attachments      string = "ticket.listAttachments"
+	ticket_get_attachment        string = "ticket.getAttachment"
+	ticket_put_attachment        string = "ticket.putAttachment"
+	ticket_delete_attachment     string = "ticket.deleteAttachment"
+	ticket_get_ticket_fields     string = "ticket.getTicketFields"
 )
 
 // TicketService represents ticket API service.
 type TicketService struct {
-	rpc       RpcClient
-	Component *TicketComponentService
+	rpc        RpcClient
+	Component  *TicketComponentService
+	Milestone  *TicketMilestoneService
+	Version    *TicketVersionService
+	Priority   *TicketEnumService
+	Resolution *TicketEnumService
+	Severity   *TicketEnumService
+	Type       *TicketEnumService
+	Status     *TicketEnumService
+}
+
+// Ticket represents the ticket returned by ticket.get, ticket.update.
+type Ticket struct {
+	ID          int
+	TimeCreated time.Time
+	TimeChanged time.Time
+	Attributes  map[string]interface{}
+}
+
+// TicketAction represents a workflow action returned by ticket.getActions.
+type TicketAction struct {
+	Name        string
+	Label       string
+	Hints       string
+	InputFields []TicketActionInput
+}
+
+// TicketActionInput represents an input field of TicketAction, such as "action_resolve_resolve_resolution".
+// Options is empty if the field accepts any value.
+type TicketActionInput struct {
+	Name    string
+	Value   string
+	Options []string
+}
+
+// TicketFieldType represents the type of a ticket field.
+type TicketFieldType string
+
+const (
+	TicketFieldText     TicketFieldType = "text"
+	TicketFieldSelect   TicketFieldType = "select"
+	TicketFieldRadio    TicketFieldType = "radio"
+	TicketFieldCheckbox TicketFieldType = "checkbox"
+	TicketFieldTextarea TicketFieldType = "textarea"
+	TicketFieldTime     TicketFieldType = "time"
+)
+
+// TicketField represents the field descriptor returned by ticket.getTicketFields.
+type TicketField struct {
+	Name     string          `xmlrpc:"name"`
+	Label    string          `xmlrpc:"label"`
+	Type     TicketFieldType `xmlrpc:"type"`
+	Options  []string        `xmlrpc:"options"`
+	Value    string          `xmlrpc:"value"`
+	Optional bool            `xmlrpc:"optional"`
+	Custom   bool            `xmlrpc:"custom"`
+	Order    int             `xmlrpc:"order"`
+}
+
+// TicketAttachment represents the attachment info returned by ticket.listAttachments.
+type TicketAttachment struct {
+	Filename    string
+	Description string
+	Size        int
+	Time        time.Time
+	Author      string
+}
+
+// TicketChange represents an entry returned by ticket.changeLog.
+type TicketChange struct {
+	Time      time.Time
+	Author    string
+	Field     string
+	OldValue  string
+	NewValue  string
+	Permanent bool
+}
+
+// TicketChangeSet represents the changes made at once by an author, as shown in the ticket page.
+type TicketChangeSet struct {
+	Time    time.Time
+	Author  string
+	Changes []TicketChange
 }
 
 // newTicketService creates new TicketService instance.
@@ -37,163 +122,607 @@ func newTicketService(rpc RpcClient) (*TicketService, error) {
 	if err != nil {
 		return nil, err
 	}
+	milestone, err := newTicketMilestoneService(rpc)
+	if err != nil {
+		return nil, err
+	}
+	version, err := newTicketVersionService(rpc)
+	if err != nil {
+		return nil, err
+	}
+	priority, err := newTicketEnumService(rpc, ticket_priority)
+	if err != nil {
+		return nil, err
+	}
+	resolution, err := newTicketEnumService(rpc, ticket_resolution)
+	if err != nil {
+		return nil, err
+	}
+	severity, err := newTicketEnumService(rpc, ticket_severity)
+	if err != nil {
+		return nil, err
+	}
+	ticketType, err := newTicketEnumService(rpc, ticket_type)
+	if err != nil {
+		return nil, err
+	}
+	status, err := newTicketEnumService(rpc, ticket_status)
+	if err != nil {
+		return nil, err
+	}
 
 	return &TicketService{
-		rpc:       rpc,
-		Component: component,
+		rpc:        rpc,
+		Component:  component,
+		Milestone:  milestone,
+		Version:    version,
+		Priority:   priority,
+		Resolution: resolution,
+		Severity:   severity,
+		Type:       ticketType,
+		Status:     status,
 	}, nil
 }
 
-// Query calls ticket.query.
-func (t *TicketService) Query(qstr) (string, error) {
-	args := packArgs()
-	var reply string
+// Query calls ticket.query and returns the ids of matched tickets.
+// If query is nil, the server default "status!=closed" is used.
+// The error of the query reported by TicketQuery.Err is returned without calling.
+func (t *TicketService) Query(query *TicketQuery) ([]int, error) {
+	var qstr *string
+	if query != nil {
+		if err := query.Err(); err != nil {
+			return nil, err
+		}
+		qstr = String(query.String())
+	}
+	args := packArgs(qstr)
+	var reply []int
 	if err := t.rpc.Call(ticket_query, args, &reply); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	return reply, nil
 }
 
-//  calls ticket..
-func (t *TicketService) GetRecentChanges() (string, error) {
-	args := packArgs()
-	var reply string
-	if err := t.rpc.Call(ticket_query, args, &reply); err != nil {
-		return "", err
+// GetRecentChanges calls ticket.getRecentChanges and returns the ids of tickets changed since the time.
+func (t *TicketService) GetRecentChanges(since *time.Time) ([]int, error) {
+	args := packArgs(since)
+	var reply []int
+	if err := t.rpc.Call(ticket_get_recent_changes, args, &reply); err != nil {
+		return nil, err
 	}
 
 	return reply, nil
 }
 
-//  calls ticket..
-func (t *TicketService) GetAvailableActions() (string, error) {
-	args := packArgs()
-	var reply string
-	if err := t.rpc.Call(ticket_query, args, &reply); err != nil {
-		return "", err
+// GetAvailableActions calls ticket.getAvailableActions.
+// NOTE: This API is deprecated on the server in favor of ticket.getActions, which also returns the input fields.
+func (t *TicketService) GetAvailableActions(id *int) ([]string, error) {
+	args := packArgs(id)
+	var reply []string
+	if err := t.rpc.Call(ticket_get_available_actions, args, &reply); err != nil {
+		return nil, err
 	}
 
 	return reply, nil
 }
 
-//  calls ticket..
-func (t *TicketService) GetActions() (string, error) {
-	args := packArgs()
-	var reply string
-	if err := t.rpc.Call(ticket_query, args, &reply); err != nil {
-		return "", err
+// GetActions calls ticket.getActions.
+func (t *TicketService) GetActions(id *int) ([]TicketAction, error) {
+	args := packArgs(id)
+	var rawReply [][]interface{}
+	if err := t.rpc.Call(ticket_get_actions, args, &rawReply); err != nil {
+		return nil, err
+	}
+
+	reply := make([]TicketAction, 0, len(rawReply))
+	for _, elem := range rawReply {
+		if len(elem) != 4 {
+			return nil, fmt.Errorf("%s: unexpected reply form. got=%v", ticket_get_actions, elem)
+		}
+		var action TicketAction
+		if isNil(elem[0]) {
+			// do nothing
+		} else if name, ok := elem[0].(string); ok {
+			action.Name = name
+		} else {
+			return nil, fmt.Errorf("%s: unexpected Name type. got=%v", ticket_get_actions, elem[0])
+		}
+		if isNil(elem[1]) {
+			// do nothing
+		} else if label, ok := elem[1].(string); ok {
+			action.Label = label
+		} else {
+			return nil, fmt.Errorf("%s: unexpected Label type. got=%v", ticket_get_actions, elem[1])
+		}
+		if isNil(elem[2]) {
+			// do nothing
+		} else if hints, ok := elem[2].(string); ok {
+			action.Hints = hints
+		} else {
+			return nil, fmt.Errorf("%s: unexpected Hints type. got=%v", ticket_get_actions, elem[2])
+		}
+		if isNil(elem[3]) {
+			// do nothing
+		} else if fields, ok := elem[3].([]interface{}); ok {
+			for _, field := range fields {
+				input, err := decodeTicketActionInput(field)
+				if err != nil {
+					return nil, err
+				}
+				action.InputFields = append(action.InputFields, input)
+			}
+		} else {
+			return nil, fmt.Errorf("%s: unexpected InputFields type. got=%v", ticket_get_actions, elem[3])
+		}
+		reply = append(reply, action)
 	}
 
 	return reply, nil
 }
 
-//  calls ticket..
-func (t *TicketService) Get() (string, error) {
-	args := packArgs()
-	var reply string
-	if err := t.rpc.Call(ticket_query, args, &reply); err != nil {
-		return "", err
+// Get calls ticket.get.
+func (t *TicketService) Get(id *int) (Ticket, error) {
+	args := packArgs(id)
+	var rawReply []interface{}
+	if err := t.rpc.Call(ticket_get, args, &rawReply); err != nil {
+		return Ticket{}, err
 	}
 
-	return reply, nil
+	return decodeTicket(ticket_get, rawReply)
 }
 
-//  calls ticket..
-func (t *TicketService) Create() (string, error) {
-	args := packArgs()
-	var reply string
-	if err := t.rpc.Call(ticket_query, args, &reply); err != nil {
-		return "", err
+// GetMany calls ticket.get for each id in one system.multicall round trip.
+// The tickets are returned in the order of ids. If any call fails, the first error is returned.
+func (t *TicketService) GetMany(ids []int) ([]Ticket, error) {
+	if len(ids) == 0 {
+		return []Ticket{}, nil
+	}
+
+	system, err := newSystemService(t.rpc)
+	if err != nil {
+		return nil, err
+	}
+	funcs := make([]MulticallFunc, 0, len(ids))
+	for _, id := range ids {
+		id := id
+		funcs = append(funcs, func(c *Client) (interface{}, error) {
+			return c.Ticket.Get(&id)
+		})
+	}
+	results, err := system.Multicall(funcs...)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := make([]Ticket, 0, len(results))
+	for i, result := range results {
+		if result.Err != nil {
+			return nil, fmt.Errorf("%s: ticket %d: %w", ticket_get, ids[i], result.Err)
+		}
+		reply = append(reply, result.Value.(Ticket))
 	}
 
 	return reply, nil
 }
 
-//  calls ticket..
-func (t *TicketService) Update() (string, error) {
-	args := packArgs()
-	var reply string
-	if err := t.rpc.Call(ticket_query, args, &reply); err != nil {
-		return "", err
+// Create calls ticket.create and returns the id of the created ticket.
+func (t *TicketService) Create(summary *string, description *string, attributes map[string]interface{}, notify *bool, when *time.Time) (int, error) {
+	// optional args are positional, so fill the preceding one.
+	if when != nil && notify == nil {
+		notify = Bool(false)
+	}
+	args := packArgs(summary, description, &attributes, notify, when)
+	var reply int
+	if err := t.rpc.Call(ticket_create, args, &reply); err != nil {
+		return 0, err
 	}
 
 	return reply, nil
 }
 
-//  calls ticket..
-func (t *TicketService) Delete() (string, error) {
-	args := packArgs()
-	var reply string
-	if err := t.rpc.Call(ticket_query, args, &reply); err != nil {
-		return "", err
+// Update calls ticket.update and returns the updated ticket.
+// Since Trac 1.0, attributes should contain "action" and "_ts" taken from Ticket.Attributes.
+func (t *TicketService) Update(id *int, comment *string, attributes map[string]interface{}, notify *bool, author *string, when *time.Time) (Ticket, error) {
+	// optional args are positional, so fill the preceding ones.
+	if when != nil && author == nil {
+		author = String("")
+	}
+	if author != nil && notify == nil {
+		notify = Bool(false)
+	}
+	args := packArgs(id, comment, &attributes, notify, author, when)
+	var rawReply []interface{}
+	if err := t.rpc.Call(ticket_update, args, &rawReply); err != nil {
+		return Ticket{}, err
+	}
+
+	return decodeTicket(ticket_update, rawReply)
+}
+
+// UpdateWithAction calls ticket.update performing the workflow action with its input values.
+// The action and the inputs are checked against the actions returned by ticket.getActions beforehand,
+// and the input fields not given in inputs are filled with their default values.
+func (t *TicketService) UpdateWithAction(id *int, comment *string, action string, inputs map[string]string, attributes map[string]interface{}, notify *bool, author *string, when *time.Time) (Ticket, error) {
+	actions, err := t.GetActions(id)
+	if err != nil {
+		return Ticket{}, err
+	}
+
+	merged := make(map[string]interface{}, len(attributes)+len(inputs)+1)
+	for name, value := range attributes {
+		merged[name] = value
+	}
+	values, err := checkTicketAction(actions, action, inputs)
+	if err != nil {
+		return Ticket{}, err
+	}
+	for name, value := range values {
+		merged[name] = value
+	}
+	merged["action"] = action
+
+	return t.Update(id, comment, merged, notify, author, when)
+}
+
+// Delete calls ticket.delete.
+func (t *TicketService) Delete(id *int) (int, error) {
+	args := packArgs(id)
+	var reply int
+	if err := t.rpc.Call(ticket_delete, args, &reply); err != nil {
+		return 0, err
 	}
 
 	return reply, nil
 }
 
-//  calls ticket..
-func (t *TicketService) ChangeLog() (string, error) {
-	args := packArgs()
-	var reply string
-	if err := t.rpc.Call(ticket_query, args, &reply); err != nil {
-		return "", err
+// ChangeLog calls ticket.changeLog.
+func (t *TicketService) ChangeLog(id *int) ([]TicketChange, error) {
+	args := packArgs(id)
+	var rawReply [][]interface{}
+	if err := t.rpc.Call(ticket_change_log, args, &rawReply); err != nil {
+		return nil, err
+	}
+
+	reply := make([]TicketChange, 0, len(rawReply))
+	for _, elem := range rawReply {
+		if len(elem) != 6 {
+			return nil, fmt.Errorf("%s: unexpected reply form. got=%v", ticket_change_log, elem)
+		}
+		var change TicketChange
+		if isNil(elem[0]) {
+			// do nothing
+		} else if when, ok := elem[0].(time.Time); ok {
+			change.Time = when
+		} else {
+			return nil, fmt.Errorf("%s: unexpected Time type. got=%v", ticket_change_log, elem[0])
+		}
+		if isNil(elem[1]) {
+			// do nothing
+		} else if author, ok := elem[1].(string); ok {
+			change.Author = author
+		} else {
+			return nil, fmt.Errorf("%s: unexpected Author type. got=%v", ticket_change_log, elem[1])
+		}
+		if isNil(elem[2]) {
+			// do nothing
+		} else if field, ok := elem[2].(string); ok {
+			change.Field = field
+		} else {
+			return nil, fmt.Errorf("%s: unexpected Field type. got=%v", ticket_change_log, elem[2])
+		}
+		if isNil(elem[3]) {
+			// do nothing
+		} else if oldValue, ok := elem[3].(string); ok {
+			change.OldValue = oldValue
+		} else {
+			return nil, fmt.Errorf("%s: unexpected OldValue type. got=%v", ticket_change_log, elem[3])
+		}
+		if isNil(elem[4]) {
+			// do nothing
+		} else if newValue, ok := elem[4].(string); ok {
+			change.NewValue = newValue
+		} else {
+			return nil, fmt.Errorf("%s: unexpected NewValue type. got=%v", ticket_change_log, elem[4])
+		}
+		// permanent is either boolean or int depending on the Trac version.
+		if isNil(elem[5]) {
+			// do nothing
+		} else if permanent, ok := elem[5].(bool); ok {
+			change.Permanent = permanent
+		} else if permanent, ok := elem[5].(int64); ok {
+			change.Permanent = permanent != 0
+		} else {
+			return nil, fmt.Errorf("%s: unexpected Permanent type. got=%v", ticket_change_log, elem[5])
+		}
+		reply = append(reply, change)
 	}
 
 	return reply, nil
 }
 
-//  calls ticket..
-func (t *TicketService) ListAttachments() (string, error) {
-	args := packArgs()
-	var reply string
-	if err := t.rpc.Call(ticket_query, args, &reply); err != nil {
-		return "", err
+// ListAttachments calls ticket.listAttachments.
+func (t *TicketService) ListAttachments(ticket *int) ([]TicketAttachment, error) {
+	args := packArgs(ticket)
+	var rawReply [][]interface{}
+	if err := t.rpc.Call(ticket_list_attachments, args, &rawReply); err != nil {
+		return nil, err
+	}
+
+	reply := make([]TicketAttachment, 0, len(rawReply))
+	for _, elem := range rawReply {
+		if len(elem) != 5 {
+			return nil, fmt.Errorf("%s: unexpected reply form. got=%v", ticket_list_attachments, elem)
+		}
+		var attachment TicketAttachment
+		if isNil(elem[0]) {
+			// do nothing
+		} else if filename, ok := elem[0].(string); ok {
+			attachment.Filename = filename
+		} else {
+			return nil, fmt.Errorf("%s: unexpected Filename type. got=%v", ticket_list_attachments, elem[0])
+		}
+		if isNil(elem[1]) {
+			// do nothing
+		} else if description, ok := elem[1].(string); ok {
+			attachment.Description = description
+		} else {
+			return nil, fmt.Errorf("%s: unexpected Description type. got=%v", ticket_list_attachments, elem[1])
+		}
+		if isNil(elem[2]) {
+			// do nothing
+		} else if size, ok := elem[2].(int64); ok {
+			attachment.Size = int(size)
+		} else {
+			return nil, fmt.Errorf("%s: unexpected Size type. got=%v", ticket_list_attachments, elem[2])
+		}
+		if isNil(elem[3]) {
+			// do nothing
+		} else if when, ok := elem[3].(time.Time); ok {
+			attachment.Time = when
+		} else {
+			return nil, fmt.Errorf("%s: unexpected Time type. got=%v", ticket_list_attachments, elem[3])
+		}
+		if isNil(elem[4]) {
+			// do nothing
+		} else if author, ok := elem[4].(string); ok {
+			attachment.Author = author
+		} else {
+			return nil, fmt.Errorf("%s: unexpected Author type. got=%v", ticket_list_attachments, elem[4])
+		}
+		reply = append(reply, attachment)
 	}
 
 	return reply, nil
 }
 
-//  calls ticket..
-func (t *TicketService) GetAttachment() (string, error) {
-	args := packArgs()
-	var reply string
-	if err := t.rpc.Call(ticket_query, args, &reply); err != nil {
-		return "", err
+// GetAttachment calls ticket.getAttachment.
+func (t *TicketService) GetAttachment(ticket *int, filename *string) ([]byte, error) {
+	args := packArgs(ticket, filename)
+	var replyBase64 string
+	if err := t.rpc.Call(ticket_get_attachment, args, &replyBase64); err != nil {
+		return nil, err
+	}
+
+	reply, err := base64.StdEncoding.DecodeString(replyBase64)
+	if err != nil {
+		return nil, err
 	}
 
 	return reply, nil
 }
 
-//  calls ticket..
-func (t *TicketService) PutAttachment() (string, error) {
-	args := packArgs()
+// PutAttachment calls ticket.putAttachment and returns the filename of the created attachment.
+func (t *TicketService) PutAttachment(ticket *int, filename *string, description *string, data []byte, replace *bool) (string, error) {
+	encData := base64String(base64.StdEncoding.EncodeToString(data))
+	args := packArgs(ticket, filename, description, &encData, replace)
 	var reply string
-	if err := t.rpc.Call(ticket_query, args, &reply); err != nil {
+	if err := t.rpc.Call(ticket_put_attachment, args, &reply); err != nil {
 		return "", err
 	}
 
 	return reply, nil
 }
 
-//  calls ticket..
-func (t *TicketService) DeleteAttachment() (string, error) {
-	args := packArgs()
-	var reply string
-	if err := t.rpc.Call(ticket_query, args, &reply); err != nil {
-		return "", err
+// DeleteAttachment calls ticket.deleteAttachment.
+func (t *TicketService) DeleteAttachment(ticket *int, filename *string) (bool, error) {
+	args := packArgs(ticket, filename)
+	var reply bool
+	if err := t.rpc.Call(ticket_delete_attachment, args, &reply); err != nil {
+		return false, err
 	}
 
 	return reply, nil
 }
 
-//  calls ticket..
-func (t *TicketService) GetTicketFields() (string, error) {
-	args := packArgs()
-	var reply string
-	if err := t.rpc.Call(ticket_query, args, &reply); err != nil {
-		return "", err
+// GetTicketFields calls ticket.getTicketFields.
+func (t *TicketService) GetTicketFields() ([]TicketField, error) {
+	var reply []TicketField
+	if err := t.rpc.Call(ticket_get_ticket_fields, nil, &reply); err != nil {
+		return nil, err
 	}
 
 	return reply, nil
 }
+
+// decodeTicket decodes the reply of the form [id, time_created, time_changed, attributes].
+func decodeTicket(methodName string, elem []interface{}) (Ticket, error) {
+	if len(elem) != 4 {
+		return Ticket{}, fmt.Errorf("%s: unexpected reply form. got=%v", methodName, elem)
+	}
+
+	var ticket Ticket
+	if id, ok := elem[0].(int64); ok {
+		ticket.ID = int(id)
+	} else {
+		return Ticket{}, fmt.Errorf("%s: unexpected ID type. got=%v", methodName, elem[0])
+	}
+	if isNil(elem[1]) {
+		// do nothing
+	} else if created, ok := elem[1].(time.Time); ok {
+		ticket.TimeCreated = created
+	} else {
+		return Ticket{}, fmt.Errorf("%s: unexpected TimeCreated type. got=%v", methodName, elem[1])
+	}
+	if isNil(elem[2]) {
+		// do nothing
+	} else if changed, ok := elem[2].(time.Time); ok {
+		ticket.TimeChanged = changed
+	} else {
+		return Ticket{}, fmt.Errorf("%s: unexpected TimeChanged type. got=%v", methodName, elem[2])
+	}
+	if isNil(elem[3]) {
+		ticket.Attributes = map[string]interface{}{}
+	} else if attributes, ok := elem[3].(map[string]interface{}); ok {
+		ticket.Attributes = attributes
+	} else {
+		return Ticket{}, fmt.Errorf("%s: unexpected Attributes type. got=%v", methodName, elem[3])
+	}
+
+	return ticket, nil
+}
+
+// Comment returns the comment of the change set, or empty string if not commented.
+func (c TicketChangeSet) Comment() string {
+	for _, change := range c.Changes {
+		if change.Field == "comment" {
+			return change.NewValue
+		}
+	}
+
+	return ""
+}
+
+// GroupTicketChanges groups the changes returned by ticket.changeLog by their time and author.
+// The changes should be sorted by time, as the server returns.
+func GroupTicketChanges(changes []TicketChange) []TicketChangeSet {
+	sets := []TicketChangeSet{}
+	for _, change := range changes {
+		if n := len(sets); n > 0 && sets[n-1].Time.Equal(change.Time) && sets[n-1].Author == change.Author {
+			sets[n-1].Changes = append(sets[n-1].Changes, change)
+			continue
+		}
+		sets = append(sets, TicketChangeSet{
+			Time:    change.Time,
+			Author:  change.Author,
+			Changes: []TicketChange{change},
+		})
+	}
+
+	return sets
+}
+
+// decodeTicketActionInput decodes an input field of ticket.getActions of the form [name, value, options].
+func decodeTicketActionInput(field interface{}) (TicketActionInput, error) {
+	elem, ok := field.([]interface{})
+	if !ok || len(elem) != 3 {
+		return TicketActionInput{}, fmt.Errorf("%s: unexpected input field form. got=%v", ticket_get_actions, field)
+	}
+
+	var input TicketActionInput
+	if isNil(elem[0]) {
+		// do nothing
+	} else if name, ok := elem[0].(string); ok {
+		input.Name = name
+	} else {
+		return TicketActionInput{}, fmt.Errorf("%s: unexpected input Name type. got=%v", ticket_get_actions, elem[0])
+	}
+	if isNil(elem[1]) {
+		// do nothing
+	} else if value, ok := elem[1].(string); ok {
+		input.Value = value
+	} else {
+		return TicketActionInput{}, fmt.Errorf("%s: unexpected input Value type. got=%v", ticket_get_actions, elem[1])
+	}
+	if isNil(elem[2]) {
+		// do nothing
+	} else if err := assign(&input.Options, elem[2]); err != nil {
+		return TicketActionInput{}, fmt.Errorf("%s: unexpected input Options type. got=%v", ticket_get_actions, elem[2])
+	}
+
+	return input, nil
+}
+
+// checkTicketAction checks the action and its inputs against actions,
+// and returns the input values filled with the default values.
+func checkTicketAction(actions []TicketAction, action string, inputs map[string]string) (map[string]string, error) {
+	names := make([]string, 0, len(actions))
+	for _, a := range actions {
+		if a.Name != action {
+			names = append(names, a.Name)
+			continue
+		}
+
+		values := make(map[string]string, len(a.InputFields))
+		for _, field := range a.InputFields {
+			value, ok := inputs[field.Name]
+			if !ok {
+				value = field.Value
+			}
+			if len(field.Options) > 0 && !containsString(field.Options, value) {
+				return nil, fmt.Errorf("action %s: invalid value of %s. got=%s, options=%v", action, field.Name, value, field.Options)
+			}
+			values[field.Name] = value
+		}
+		for name := range inputs {
+			if _, ok := values[name]; !ok {
+				return nil, fmt.Errorf("action %s: unknown input field %s", action, name)
+			}
+		}
+
+		return values, nil
+	}
+
+	return nil, fmt.Errorf("action %s is not available. available=%v", action, names)
+}
+
+// containsString checks the slice contains s or not.
+func containsString(slice []string, s string) bool {
+	for _, elem := range slice {
+		if elem == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckTicketAttributes checks the attributes given to ticket.create or ticket.update against fields.
+// Unknown field names and values out of the options of select, radio and checkbox fields are reported.
+// The attributes for the workflow, "action", "action_*" and "_ts", are not checked.
+func CheckTicketAttributes(fields []TicketField, attributes map[string]interface{}) error {
+	byName := make(map[string]TicketField, len(fields))
+	for _, field := range fields {
+		byName[field.Name] = field
+	}
+
+	for name, value := range attributes {
+		if name == "action" || name == "_ts" || strings.HasPrefix(name, "action_") {
+			continue
+		}
+		field, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("unknown ticket field %s", name)
+		}
+
+		str, ok := value.(string)
+		if !ok {
+			continue
+		}
+		switch field.Type {
+		case TicketFieldSelect, TicketFieldRadio:
+			if str == "" && field.Optional {
+				continue
+			}
+			if !containsString(field.Options, str) {
+				return fmt.Errorf("invalid value of ticket field %s. got=%s, options=%v", name, str, field.Options)
+			}
+		case TicketFieldCheckbox:
+			if str != "0" && str != "1" {
+				return fmt.Errorf("invalid value of ticket field %s. got=%s, expected 0 or 1", name, str)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package tracrpc
+
+import (
+	"errors"
+)
+
+const (
+	ticket_component_get_all string = "ticket.component.getAll"
+	ticket_component_get     string = "ticket.component.get"
+	ticket_component_delete  string = "ticket.component.delete"
+	ticket_component_create  string = "ticket.component.create"
+	ticket_component_update  string = "ticket.component.update"
+)
+
+// TicketComponentService represents ticket component API service.
+type TicketComponentService struct {
+	rpc RpcClient
+}
+
+// TicketComponent represents the component returned by ticket.component.get.
+type TicketComponent struct {
+	Name        string `xmlrpc:"name"`
+	Owner       string `xmlrpc:"owner"`
+	Description string `xmlrpc:"description"`
+}
+
+// TicketComponentAttributes represents attributes of ticket.component.create, ticket.component.update.
+// Nil attributes are left unchanged.
+type TicketComponentAttributes struct {
+	Name        *string `xmlrpc:"name,omitempty"`
+	Owner       *string `xmlrpc:"owner,omitempty"`
+	Description *string `xmlrpc:"description,omitempty"`
+}
+
+// newTicketComponentService creates new TicketComponentService instance.
+func newTicketComponentService(rpc RpcClient) (*TicketComponentService, error) {
+	if rpc == nil {
+		return nil, errors.New("rpc client cannot be nil")
+	}
+
+	return &TicketComponentService{
+		rpc: rpc,
+	}, nil
+}
+
+// GetAll calls ticket.component.getAll.
+func (t *TicketComponentService) GetAll() ([]string, error) {
+	var reply []string
+	if err := t.rpc.Call(ticket_component_get_all, nil, &reply); err != nil {
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+// Get calls ticket.component.get.
+func (t *TicketComponentService) Get(name *string) (TicketComponent, error) {
+	args := packArgs(name)
+	var reply TicketComponent
+	if err := t.rpc.Call(ticket_component_get, args, &reply); err != nil {
+		return TicketComponent{}, err
+	}
+
+	return reply, nil
+}
+
+// Delete calls ticket.component.delete.
+func (t *TicketComponentService) Delete(name *string) (int, error) {
+	args := packArgs(name)
+	var reply int
+	if err := t.rpc.Call(ticket_component_delete, args, &reply); err != nil {
+		return 0, err
+	}
+
+	return reply, nil
+}
+
+// Create calls ticket.component.create.
+func (t *TicketComponentService) Create(name *string, attributes TicketComponentAttributes) (int, error) {
+	args := packArgs(name, &attributes)
+	var reply int
+	if err := t.rpc.Call(ticket_component_create, args, &reply); err != nil {
+		return 0, err
+	}
+
+	return reply, nil
+}
+
+// Update calls ticket.component.update.
+func (t *TicketComponentService) Update(name *string, attributes TicketComponentAttributes) (int, error) {
+	args := packArgs(name, &attributes)
+	var reply int
+	if err := t.rpc.Call(ticket_component_update, args, &reply); err != nil {
+		return 0, err
+	}
+
+	return reply, nil
+}
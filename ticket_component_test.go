@@ -0,0 +1,191 @@
+package tracrpc
+
+import (
+	"reflect"
+	"testing"
+)
+
+const intReply = `<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><int>0</int></value>
+</param>
+</params>
+</methodResponse>`
+
+func TestNewTicketComponentService(t *testing.T) {
+	tests := []struct {
+		name      string
+		rpcClient RpcClient
+		wantErr   bool
+	}{
+		{
+			name:      "OK",
+			rpcClient: &RpcClientMock{},
+			wantErr:   false,
+		},
+		{
+			name:      "NG",
+			rpcClient: nil,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTicketComponentService(tt.rpcClient)
+			if err != nil {
+				if tt.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestTicketComponentGetAll(t *testing.T) {
+	test := struct {
+		reply    string
+		expected []string
+	}{
+		`<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><array><data>
+<value><string>component1</string></value>
+<value><string>component2</string></value>
+</data></array></value>
+</param>
+</params>
+</methodResponse>`,
+		[]string{"component1", "component2"},
+	}
+
+	c := NewTestClient(ticket_component_get_all, nil, test.reply)
+	res, err := c.Ticket.Component.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, test.expected) {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketComponentGet(t *testing.T) {
+	test := struct {
+		name     *string
+		reply    string
+		expected TicketComponent
+	}{
+		String("component1"),
+		`<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><struct>
+<member>
+<name>owner</name>
+<value><string>n_ii</string></value>
+</member>
+<member>
+<name>name</name>
+<value><string>component1</string></value>
+</member>
+<member>
+<name>description</name>
+<value><string>ouch</string></value>
+</member>
+</struct></value>
+</param>
+</params>
+</methodResponse>`,
+		TicketComponent{
+			Name:        "component1",
+			Owner:       "n_ii",
+			Description: "ouch",
+		},
+	}
+
+	c := NewTestClient(ticket_component_get, packArgs(test.name), test.reply)
+	res, err := c.Ticket.Component.Get(test.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != test.expected {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketComponentDelete(t *testing.T) {
+	test := struct {
+		name     *string
+		reply    string
+		expected int
+	}{
+		String("component1"),
+		intReply,
+		0,
+	}
+
+	c := NewTestClient(ticket_component_delete, packArgs(test.name), test.reply)
+	res, err := c.Ticket.Component.Delete(test.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != test.expected {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketComponentCreate(t *testing.T) {
+	test := struct {
+		name       *string
+		attributes TicketComponentAttributes
+		reply      string
+		expected   int
+	}{
+		String("component3"),
+		TicketComponentAttributes{
+			Owner: String("yoshi"),
+		},
+		intReply,
+		0,
+	}
+
+	c := NewTestClient(ticket_component_create, packArgs(test.name, &test.attributes), test.reply)
+	res, err := c.Ticket.Component.Create(test.name, test.attributes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != test.expected {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketComponentUpdate(t *testing.T) {
+	test := struct {
+		name       *string
+		attributes TicketComponentAttributes
+		reply      string
+		expected   int
+	}{
+		String("component3"),
+		TicketComponentAttributes{
+			Description: String("nobu"),
+		},
+		intReply,
+		0,
+	}
+
+	c := NewTestClient(ticket_component_update, packArgs(test.name, &test.attributes), test.reply)
+	res, err := c.Ticket.Component.Update(test.name, test.attributes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != test.expected {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
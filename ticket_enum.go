@@ -0,0 +1,95 @@
+package tracrpc
+
+import (
+	"errors"
+)
+
+const (
+	ticket_priority   string = "ticket.priority"
+	ticket_resolution string = "ticket.resolution"
+	ticket_severity   string = "ticket.severity"
+	ticket_type       string = "ticket.type"
+	ticket_status     string = "ticket.status"
+)
+
+const (
+	ticket_enum_get_all string = ".getAll"
+	ticket_enum_get     string = ".get"
+	ticket_enum_delete  string = ".delete"
+	ticket_enum_create  string = ".create"
+	ticket_enum_update  string = ".update"
+)
+
+// TicketEnumService represents API service of ticket enums,
+// which are ticket.priority, ticket.resolution, ticket.severity, ticket.type and ticket.status.
+// NOTE: ticket.status only supports GetAll.
+type TicketEnumService struct {
+	rpc  RpcClient
+	name string
+}
+
+// newTicketEnumService creates new TicketEnumService instance for the enum name such as "ticket.priority".
+func newTicketEnumService(rpc RpcClient, name string) (*TicketEnumService, error) {
+	if rpc == nil {
+		return nil, errors.New("rpc client cannot be nil")
+	}
+
+	return &TicketEnumService{
+		rpc:  rpc,
+		name: name,
+	}, nil
+}
+
+// GetAll calls getAll of the enum.
+func (t *TicketEnumService) GetAll() ([]string, error) {
+	var reply []string
+	if err := t.rpc.Call(t.name+ticket_enum_get_all, nil, &reply); err != nil {
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+// Get calls get of the enum and returns the value of the enum.
+func (t *TicketEnumService) Get(name *string) (string, error) {
+	args := packArgs(name)
+	var reply string
+	if err := t.rpc.Call(t.name+ticket_enum_get, args, &reply); err != nil {
+		return "", err
+	}
+
+	return reply, nil
+}
+
+// Delete calls delete of the enum.
+func (t *TicketEnumService) Delete(name *string) (int, error) {
+	args := packArgs(name)
+	var reply int
+	if err := t.rpc.Call(t.name+ticket_enum_delete, args, &reply); err != nil {
+		return 0, err
+	}
+
+	return reply, nil
+}
+
+// Create calls create of the enum.
+func (t *TicketEnumService) Create(name *string, value *string) (int, error) {
+	args := packArgs(name, value)
+	var reply int
+	if err := t.rpc.Call(t.name+ticket_enum_create, args, &reply); err != nil {
+		return 0, err
+	}
+
+	return reply, nil
+}
+
+// Update calls update of the enum.
+func (t *TicketEnumService) Update(name *string, value *string) (int, error) {
+	args := packArgs(name, value)
+	var reply int
+	if err := t.rpc.Call(t.name+ticket_enum_update, args, &reply); err != nil {
+		return 0, err
+	}
+
+	return reply, nil
+}
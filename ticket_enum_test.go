@@ -0,0 +1,170 @@
+package tracrpc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewTicketEnumService(t *testing.T) {
+	tests := []struct {
+		name      string
+		rpcClient RpcClient
+		wantErr   bool
+	}{
+		{
+			name:      "OK",
+			rpcClient: &RpcClientMock{},
+			wantErr:   false,
+		},
+		{
+			name:      "NG",
+			rpcClient: nil,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTicketEnumService(tt.rpcClient, ticket_priority)
+			if err != nil {
+				if tt.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestTicketEnumGetAll(t *testing.T) {
+	reply := `<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><array><data>
+<value><string>blocker</string></value>
+<value><string>minor</string></value>
+</data></array></value>
+</param>
+</params>
+</methodResponse>`
+	expected := []string{"blocker", "minor"}
+	tests := []struct {
+		name    string
+		service func(c *Client) *TicketEnumService
+	}{
+		{ticket_priority, func(c *Client) *TicketEnumService { return c.Ticket.Priority }},
+		{ticket_resolution, func(c *Client) *TicketEnumService { return c.Ticket.Resolution }},
+		{ticket_severity, func(c *Client) *TicketEnumService { return c.Ticket.Severity }},
+		{ticket_type, func(c *Client) *TicketEnumService { return c.Ticket.Type }},
+		{ticket_status, func(c *Client) *TicketEnumService { return c.Ticket.Status }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewTestClient(tt.name+ticket_enum_get_all, nil, reply)
+			res, err := tt.service(c).GetAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(res, expected) {
+				t.Fatalf("unexpected result. expected=%v, got=%v", expected, res)
+			}
+		})
+	}
+}
+
+func TestTicketEnumGet(t *testing.T) {
+	test := struct {
+		name     *string
+		reply    string
+		expected string
+	}{
+		String("blocker"),
+		`<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><string>1</string></value>
+</param>
+</params>
+</methodResponse>`,
+		"1",
+	}
+
+	c := NewTestClient(ticket_priority+ticket_enum_get, packArgs(test.name), test.reply)
+	res, err := c.Ticket.Priority.Get(test.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != test.expected {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketEnumDelete(t *testing.T) {
+	test := struct {
+		name     *string
+		reply    string
+		expected int
+	}{
+		String("blocker"),
+		intReply,
+		0,
+	}
+
+	c := NewTestClient(ticket_severity+ticket_enum_delete, packArgs(test.name), test.reply)
+	res, err := c.Ticket.Severity.Delete(test.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != test.expected {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketEnumCreate(t *testing.T) {
+	test := struct {
+		name     *string
+		value    *string
+		reply    string
+		expected int
+	}{
+		String("worksforme"),
+		String("6"),
+		intReply,
+		0,
+	}
+
+	c := NewTestClient(ticket_resolution+ticket_enum_create, packArgs(test.name, test.value), test.reply)
+	res, err := c.Ticket.Resolution.Create(test.name, test.value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != test.expected {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketEnumUpdate(t *testing.T) {
+	test := struct {
+		name     *string
+		value    *string
+		reply    string
+		expected int
+	}{
+		String("defect"),
+		String("2"),
+		intReply,
+		0,
+	}
+
+	c := NewTestClient(ticket_type+ticket_enum_update, packArgs(test.name, test.value), test.reply)
+	res, err := c.Ticket.Type.Update(test.name, test.value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != test.expected {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
@@ -0,0 +1,126 @@
+package tracrpc
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	ticket_milestone_get_all string = "ticket.milestone.getAll"
+	ticket_milestone_get     string = "ticket.milestone.get"
+	ticket_milestone_delete  string = "ticket.milestone.delete"
+	ticket_milestone_create  string = "ticket.milestone.create"
+	ticket_milestone_update  string = "ticket.milestone.update"
+)
+
+// TicketMilestoneService represents ticket milestone API service.
+type TicketMilestoneService struct {
+	rpc RpcClient
+}
+
+// TicketMilestone represents the milestone returned by ticket.milestone.get.
+// Due and Completed are zero if not set.
+type TicketMilestone struct {
+	Name        string
+	Due         time.Time
+	Completed   time.Time
+	Description string
+}
+
+// TicketMilestoneAttributes represents attributes of ticket.milestone.create, ticket.milestone.update.
+// Nil attributes are left unchanged.
+type TicketMilestoneAttributes struct {
+	Name        *string    `xmlrpc:"name,omitempty"`
+	Due         *time.Time `xmlrpc:"due,omitempty"`
+	Completed   *time.Time `xmlrpc:"completed,omitempty"`
+	Description *string    `xmlrpc:"description,omitempty"`
+}
+
+// newTicketMilestoneService creates new TicketMilestoneService instance.
+func newTicketMilestoneService(rpc RpcClient) (*TicketMilestoneService, error) {
+	if rpc == nil {
+		return nil, errors.New("rpc client cannot be nil")
+	}
+
+	return &TicketMilestoneService{
+		rpc: rpc,
+	}, nil
+}
+
+// GetAll calls ticket.milestone.getAll.
+func (t *TicketMilestoneService) GetAll() ([]string, error) {
+	var reply []string
+	if err := t.rpc.Call(ticket_milestone_get_all, nil, &reply); err != nil {
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+// Get calls ticket.milestone.get.
+// NOTE: The server returns 0 instead of dateTime for the unset due and completed.
+func (t *TicketMilestoneService) Get(name *string) (TicketMilestone, error) {
+	args := packArgs(name)
+	var rawReply map[string]interface{}
+	if err := t.rpc.Call(ticket_milestone_get, args, &rawReply); err != nil {
+		return TicketMilestone{}, err
+	}
+
+	var reply TicketMilestone
+	if isNil(rawReply["name"]) {
+		// do nothing
+	} else if name, ok := rawReply["name"].(string); ok {
+		reply.Name = name
+	} else {
+		return TicketMilestone{}, fmt.Errorf("%s: unexpected Name type. got=%v", ticket_milestone_get, rawReply["name"])
+	}
+	if due, ok := rawReply["due"].(time.Time); ok {
+		reply.Due = due
+	}
+	if completed, ok := rawReply["completed"].(time.Time); ok {
+		reply.Completed = completed
+	}
+	if isNil(rawReply["description"]) {
+		// do nothing
+	} else if description, ok := rawReply["description"].(string); ok {
+		reply.Description = description
+	} else {
+		return TicketMilestone{}, fmt.Errorf("%s: unexpected Description type. got=%v", ticket_milestone_get, rawReply["description"])
+	}
+
+	return reply, nil
+}
+
+// Delete calls ticket.milestone.delete.
+func (t *TicketMilestoneService) Delete(name *string) (int, error) {
+	args := packArgs(name)
+	var reply int
+	if err := t.rpc.Call(ticket_milestone_delete, args, &reply); err != nil {
+		return 0, err
+	}
+
+	return reply, nil
+}
+
+// Create calls ticket.milestone.create.
+func (t *TicketMilestoneService) Create(name *string, attributes TicketMilestoneAttributes) (int, error) {
+	args := packArgs(name, &attributes)
+	var reply int
+	if err := t.rpc.Call(ticket_milestone_create, args, &reply); err != nil {
+		return 0, err
+	}
+
+	return reply, nil
+}
+
+// Update calls ticket.milestone.update.
+func (t *TicketMilestoneService) Update(name *string, attributes TicketMilestoneAttributes) (int, error) {
+	args := packArgs(name, &attributes)
+	var reply int
+	if err := t.rpc.Call(ticket_milestone_update, args, &reply); err != nil {
+		return 0, err
+	}
+
+	return reply, nil
+}
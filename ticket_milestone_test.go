@@ -0,0 +1,188 @@
+package tracrpc
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewTicketMilestoneService(t *testing.T) {
+	tests := []struct {
+		name      string
+		rpcClient RpcClient
+		wantErr   bool
+	}{
+		{
+			name:      "OK",
+			rpcClient: &RpcClientMock{},
+			wantErr:   false,
+		},
+		{
+			name:      "NG",
+			rpcClient: nil,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTicketMilestoneService(tt.rpcClient)
+			if err != nil {
+				if tt.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestTicketMilestoneGetAll(t *testing.T) {
+	test := struct {
+		reply    string
+		expected []string
+	}{
+		`<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><array><data>
+<value><string>milestone1</string></value>
+<value><string>milestone2</string></value>
+</data></array></value>
+</param>
+</params>
+</methodResponse>`,
+		[]string{"milestone1", "milestone2"},
+	}
+
+	c := NewTestClient(ticket_milestone_get_all, nil, test.reply)
+	res, err := c.Ticket.Milestone.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, test.expected) {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketMilestoneGet(t *testing.T) {
+	test := struct {
+		name     *string
+		reply    string
+		expected TicketMilestone
+	}{
+		String("milestone1"),
+		`<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><struct>
+<member>
+<name>completed</name>
+<value><int>0</int></value>
+</member>
+<member>
+<name>due</name>
+<value><dateTime.iso8601>18671109T00:00:00</dateTime.iso8601></value>
+</member>
+<member>
+<name>name</name>
+<value><string>milestone1</string></value>
+</member>
+<member>
+<name>description</name>
+<value><string>taisei hokan</string></value>
+</member>
+</struct></value>
+</param>
+</params>
+</methodResponse>`,
+		TicketMilestone{
+			Name:        "milestone1",
+			Due:         time.Date(1867, time.November, 9, 0, 0, 0, 0, time.UTC),
+			Description: "taisei hokan",
+		},
+	}
+
+	c := NewTestClient(ticket_milestone_get, packArgs(test.name), test.reply)
+	res, err := c.Ticket.Milestone.Get(test.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != test.expected {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketMilestoneCreate(t *testing.T) {
+	test := struct {
+		name       *string
+		attributes TicketMilestoneAttributes
+		reply      string
+		expected   int
+	}{
+		String("milestone3"),
+		TicketMilestoneAttributes{
+			Due:         Time(time.Date(1867, time.November, 9, 0, 0, 0, 0, time.UTC)),
+			Description: String("taisei hokan"),
+		},
+		intReply,
+		0,
+	}
+
+	c := NewTestClient(ticket_milestone_create, packArgs(test.name, &test.attributes), test.reply)
+	res, err := c.Ticket.Milestone.Create(test.name, test.attributes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != test.expected {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketMilestoneUpdate(t *testing.T) {
+	test := struct {
+		name       *string
+		attributes TicketMilestoneAttributes
+		reply      string
+		expected   int
+	}{
+		String("milestone3"),
+		TicketMilestoneAttributes{
+			Completed: Time(time.Date(1868, time.January, 3, 0, 0, 0, 0, time.UTC)),
+		},
+		intReply,
+		0,
+	}
+
+	c := NewTestClient(ticket_milestone_update, packArgs(test.name, &test.attributes), test.reply)
+	res, err := c.Ticket.Milestone.Update(test.name, test.attributes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != test.expected {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketMilestoneDelete(t *testing.T) {
+	test := struct {
+		name     *string
+		reply    string
+		expected int
+	}{
+		String("milestone3"),
+		intReply,
+		0,
+	}
+
+	c := NewTestClient(ticket_milestone_delete, packArgs(test.name), test.reply)
+	res, err := c.Ticket.Milestone.Delete(test.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != test.expected {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
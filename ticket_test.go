@@ -0,0 +1,870 @@
+package tracrpc
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const ticketReply = `<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><array><data>
+<value><int>7</int></value>
+<value><dateTime.iso8601>18600324T00:00:00</dateTime.iso8601></value>
+<value><dateTime.iso8601>18671109T00:00:00</dateTime.iso8601></value>
+<value><struct>
+<member>
+<name>summary</name>
+<value><string>sakuradamon</string></value>
+</member>
+<member>
+<name>status</name>
+<value><string>new</string></value>
+</member>
+<member>
+<name>_ts</name>
+<value><string>1234567890</string></value>
+</member>
+</struct></value>
+</data></array></value>
+</param>
+</params>
+</methodResponse>`
+
+var ticketExpected = Ticket{
+	ID:          7,
+	TimeCreated: time.Date(1860, time.March, 24, 0, 0, 0, 0, time.UTC),
+	TimeChanged: time.Date(1867, time.November, 9, 0, 0, 0, 0, time.UTC),
+	Attributes: map[string]interface{}{
+		"summary": "sakuradamon",
+		"status":  "new",
+		"_ts":     "1234567890",
+	},
+}
+
+func TestNewTicketService(t *testing.T) {
+	tests := []struct {
+		name      string
+		rpcClient RpcClient
+		wantErr   bool
+	}{
+		{
+			name:      "OK",
+			rpcClient: &RpcClientMock{},
+			wantErr:   false,
+		},
+		{
+			name:      "NG",
+			rpcClient: nil,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTicketService(tt.rpcClient)
+			if err != nil {
+				if tt.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestTicketGet(t *testing.T) {
+	test := struct {
+		id       *int
+		reply    string
+		expected Ticket
+	}{
+		Int(7),
+		ticketReply,
+		ticketExpected,
+	}
+
+	c := NewTestClient(ticket_get, packArgs(test.id), test.reply)
+	res, err := c.Ticket.Get(test.id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, test.expected) {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketCreate(t *testing.T) {
+	test := struct {
+		summary     *string
+		description *string
+		attributes  map[string]interface{}
+		notify      *bool
+		when        *time.Time
+		reply       string
+		expected    int
+	}{
+		String("sakuradamon"),
+		String("ouch"),
+		map[string]interface{}{"owner": "n_ii"},
+		nil,
+		Time(time.Date(1860, time.March, 24, 0, 0, 0, 0, time.UTC)),
+		`<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><int>7</int></value>
+</param>
+</params>
+</methodResponse>`,
+		7,
+	}
+
+	c := NewTestClient(ticket_create, packArgs(test.summary, test.description, &test.attributes, Bool(false), test.when), test.reply)
+	res, err := c.Ticket.Create(test.summary, test.description, test.attributes, test.notify, test.when)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != test.expected {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketUpdate(t *testing.T) {
+	test := struct {
+		id         *int
+		comment    *string
+		attributes map[string]interface{}
+		notify     *bool
+		author     *string
+		reply      string
+		expected   Ticket
+	}{
+		Int(7),
+		String("nobu"),
+		map[string]interface{}{"action": "leave", "_ts": "1234567890"},
+		nil,
+		String("yoshi"),
+		ticketReply,
+		ticketExpected,
+	}
+
+	c := NewTestClient(ticket_update, packArgs(test.id, test.comment, &test.attributes, Bool(false), test.author), test.reply)
+	res, err := c.Ticket.Update(test.id, test.comment, test.attributes, test.notify, test.author, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, test.expected) {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketDelete(t *testing.T) {
+	test := struct {
+		id       *int
+		reply    string
+		expected int
+	}{
+		Int(7),
+		`<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><int>0</int></value>
+</param>
+</params>
+</methodResponse>`,
+		0,
+	}
+
+	c := NewTestClient(ticket_delete, packArgs(test.id), test.reply)
+	res, err := c.Ticket.Delete(test.id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != test.expected {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketQuery(t *testing.T) {
+	test := struct {
+		query    *TicketQuery
+		reply    string
+		expected []int
+	}{
+		NewTicketQuery().Is("status", "new", "assigned").OrderBy("priority", false).Max(0),
+		`<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><array><data>
+<value><int>1</int></value>
+<value><int>7</int></value>
+</data></array></value>
+</param>
+</params>
+</methodResponse>`,
+		[]int{1, 7},
+	}
+
+	c := NewTestClient(ticket_query, packArgs(String("status=new|assigned&order=priority&max=0")), test.reply)
+	res, err := c.Ticket.Query(test.query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, test.expected) {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketChangeLog(t *testing.T) {
+	test := struct {
+		id       *int
+		reply    string
+		expected []TicketChange
+	}{
+		Int(7),
+		`<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><array><data>
+<value><array><data>
+<value><dateTime.iso8601>18600324T00:00:00</dateTime.iso8601></value>
+<value><string>n_ii</string></value>
+<value><string>status</string></value>
+<value><string>new</string></value>
+<value><string>closed</string></value>
+<value><int>1</int></value>
+</data></array></value>
+<value><array><data>
+<value><dateTime.iso8601>18671109T00:00:00</dateTime.iso8601></value>
+<value><string>yoshi</string></value>
+<value><string>attachment</string></value>
+<value><string></string></value>
+<value><string>taisei.txt</string></value>
+<value><boolean>0</boolean></value>
+</data></array></value>
+</data></array></value>
+</param>
+</params>
+</methodResponse>`,
+		[]TicketChange{
+			{
+				Time:      time.Date(1860, time.March, 24, 0, 0, 0, 0, time.UTC),
+				Author:    "n_ii",
+				Field:     "status",
+				OldValue:  "new",
+				NewValue:  "closed",
+				Permanent: true,
+			},
+			{
+				Time:      time.Date(1867, time.November, 9, 0, 0, 0, 0, time.UTC),
+				Author:    "yoshi",
+				Field:     "attachment",
+				NewValue:  "taisei.txt",
+				Permanent: false,
+			},
+		},
+	}
+
+	c := NewTestClient(ticket_change_log, packArgs(test.id), test.reply)
+	res, err := c.Ticket.ChangeLog(test.id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, test.expected) {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestGroupTicketChanges(t *testing.T) {
+	t1 := time.Date(1860, time.March, 24, 0, 0, 0, 0, time.UTC)
+	t2 := time.Date(1867, time.November, 9, 0, 0, 0, 0, time.UTC)
+	changes := []TicketChange{
+		{Time: t1, Author: "n_ii", Field: "status", OldValue: "new", NewValue: "closed"},
+		{Time: t1, Author: "n_ii", Field: "comment", OldValue: "1", NewValue: "ouch"},
+		{Time: t1, Author: "yoshi", Field: "owner", NewValue: "yoshi"},
+		{Time: t2, Author: "yoshi", Field: "status", OldValue: "closed", NewValue: "reopened"},
+	}
+	expected := []TicketChangeSet{
+		{Time: t1, Author: "n_ii", Changes: changes[0:2]},
+		{Time: t1, Author: "yoshi", Changes: changes[2:3]},
+		{Time: t2, Author: "yoshi", Changes: changes[3:4]},
+	}
+
+	res := GroupTicketChanges(changes)
+	if !reflect.DeepEqual(res, expected) {
+		t.Fatalf("unexpected result. expected=%v, got=%v", expected, res)
+	}
+	if comment := res[0].Comment(); comment != "ouch" {
+		t.Fatalf("unexpected comment. expected=%v, got=%v", "ouch", comment)
+	}
+	if comment := res[1].Comment(); comment != "" {
+		t.Fatalf("unexpected comment. expected=%v, got=%v", "", comment)
+	}
+}
+
+const ticketActionsReply = `<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><array><data>
+<value><array><data>
+<value><string>leave</string></value>
+<value><string>leave</string></value>
+<value><string>as new</string></value>
+<value><array><data>
+</data></array></value>
+</data></array></value>
+<value><array><data>
+<value><string>resolve</string></value>
+<value><string>resolve</string></value>
+<value><string>The resolution will be set.</string></value>
+<value><array><data>
+<value><array><data>
+<value><string>action_resolve_resolve_resolution</string></value>
+<value><string>fixed</string></value>
+<value><array><data>
+<value><string>fixed</string></value>
+<value><string>wontfix</string></value>
+</data></array></value>
+</data></array></value>
+</data></array></value>
+</data></array></value>
+</data></array></value>
+</param>
+</params>
+</methodResponse>`
+
+func TestTicketGetAvailableActions(t *testing.T) {
+	test := struct {
+		id       *int
+		reply    string
+		expected []string
+	}{
+		Int(7),
+		`<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><array><data>
+<value><string>leave</string></value>
+<value><string>resolve</string></value>
+</data></array></value>
+</param>
+</params>
+</methodResponse>`,
+		[]string{"leave", "resolve"},
+	}
+
+	c := NewTestClient(ticket_get_available_actions, packArgs(test.id), test.reply)
+	res, err := c.Ticket.GetAvailableActions(test.id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, test.expected) {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketGetActions(t *testing.T) {
+	test := struct {
+		id       *int
+		reply    string
+		expected []TicketAction
+	}{
+		Int(7),
+		ticketActionsReply,
+		[]TicketAction{
+			{
+				Name:  "leave",
+				Label: "leave",
+				Hints: "as new",
+			},
+			{
+				Name:  "resolve",
+				Label: "resolve",
+				Hints: "The resolution will be set.",
+				InputFields: []TicketActionInput{
+					{
+						Name:    "action_resolve_resolve_resolution",
+						Value:   "fixed",
+						Options: []string{"fixed", "wontfix"},
+					},
+				},
+			},
+		},
+	}
+
+	c := NewTestClient(ticket_get_actions, packArgs(test.id), test.reply)
+	res, err := c.Ticket.GetActions(test.id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, test.expected) {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestCheckTicketAction(t *testing.T) {
+	actions := []TicketAction{
+		{Name: "leave"},
+		{
+			Name: "resolve",
+			InputFields: []TicketActionInput{
+				{
+					Name:    "action_resolve_resolve_resolution",
+					Value:   "fixed",
+					Options: []string{"fixed", "wontfix"},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name     string
+		action   string
+		inputs   map[string]string
+		expected map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "Default",
+			action:   "resolve",
+			inputs:   nil,
+			expected: map[string]string{"action_resolve_resolve_resolution": "fixed"},
+		},
+		{
+			name:     "Given",
+			action:   "resolve",
+			inputs:   map[string]string{"action_resolve_resolve_resolution": "wontfix"},
+			expected: map[string]string{"action_resolve_resolve_resolution": "wontfix"},
+		},
+		{
+			name:    "InvalidOption",
+			action:  "resolve",
+			inputs:  map[string]string{"action_resolve_resolve_resolution": "duplicate"},
+			wantErr: true,
+		},
+		{
+			name:    "UnknownInput",
+			action:  "leave",
+			inputs:  map[string]string{"action_resolve_resolve_resolution": "fixed"},
+			wantErr: true,
+		},
+		{
+			name:    "UnknownAction",
+			action:  "reassign",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := checkTicketAction(actions, tt.action, tt.inputs)
+			if err != nil {
+				if tt.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				t.Fatal("expected error, got nil")
+			}
+			if !reflect.DeepEqual(res, tt.expected) {
+				t.Fatalf("unexpected result. expected=%v, got=%v", tt.expected, res)
+			}
+		})
+	}
+}
+
+func TestTicketGetTicketFields(t *testing.T) {
+	test := struct {
+		reply    string
+		expected []TicketField
+	}{
+		`<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><array><data>
+<value><struct>
+<member>
+<name>name</name>
+<value><string>summary</string></value>
+</member>
+<member>
+<name>label</name>
+<value><string>Summary</string></value>
+</member>
+<member>
+<name>type</name>
+<value><string>text</string></value>
+</member>
+</struct></value>
+<value><struct>
+<member>
+<name>name</name>
+<value><string>gate</string></value>
+</member>
+<member>
+<name>label</name>
+<value><string>Gate</string></value>
+</member>
+<member>
+<name>type</name>
+<value><string>select</string></value>
+</member>
+<member>
+<name>options</name>
+<value><array><data>
+<value><string>sakuradamon</string></value>
+<value><string>sakashitamon</string></value>
+</data></array></value>
+</member>
+<member>
+<name>value</name>
+<value><string>sakuradamon</string></value>
+</member>
+<member>
+<name>optional</name>
+<value><boolean>1</boolean></value>
+</member>
+<member>
+<name>custom</name>
+<value><boolean>1</boolean></value>
+</member>
+<member>
+<name>order</name>
+<value><int>2</int></value>
+</member>
+</struct></value>
+</data></array></value>
+</param>
+</params>
+</methodResponse>`,
+		[]TicketField{
+			{
+				Name:  "summary",
+				Label: "Summary",
+				Type:  TicketFieldText,
+			},
+			{
+				Name:     "gate",
+				Label:    "Gate",
+				Type:     TicketFieldSelect,
+				Options:  []string{"sakuradamon", "sakashitamon"},
+				Value:    "sakuradamon",
+				Optional: true,
+				Custom:   true,
+				Order:    2,
+			},
+		},
+	}
+
+	c := NewTestClient(ticket_get_ticket_fields, nil, test.reply)
+	res, err := c.Ticket.GetTicketFields()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, test.expected) {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestCheckTicketAttributes(t *testing.T) {
+	fields := []TicketField{
+		{Name: "summary", Type: TicketFieldText},
+		{Name: "gate", Type: TicketFieldSelect, Options: []string{"sakuradamon"}, Optional: true},
+		{Name: "closed", Type: TicketFieldCheckbox},
+	}
+	tests := []struct {
+		name       string
+		attributes map[string]interface{}
+		wantErr    bool
+	}{
+		{
+			name:       "OK",
+			attributes: map[string]interface{}{"summary": "ouch", "gate": "sakuradamon", "closed": "1", "action": "leave", "_ts": "1"},
+			wantErr:    false,
+		},
+		{
+			name:       "EmptyOptional",
+			attributes: map[string]interface{}{"gate": ""},
+			wantErr:    false,
+		},
+		{
+			name:       "UnknownField",
+			attributes: map[string]interface{}{"castle": "edo"},
+			wantErr:    true,
+		},
+		{
+			name:       "InvalidOption",
+			attributes: map[string]interface{}{"gate": "sakashitamon"},
+			wantErr:    true,
+		},
+		{
+			name:       "InvalidCheckbox",
+			attributes: map[string]interface{}{"closed": "yes"},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckTicketAttributes(fields, tt.attributes)
+			if err != nil {
+				if tt.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				t.Fatal("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestTicketListAttachments(t *testing.T) {
+	test := struct {
+		ticket   *int
+		reply    string
+		expected []TicketAttachment
+	}{
+		Int(7),
+		`<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><array><data>
+<value><array><data>
+<value><string>Otsu.txt</string></value>
+<value><string>build log</string></value>
+<value><int>1024</int></value>
+<value><dateTime.iso8601>18600324T00:00:00</dateTime.iso8601></value>
+<value><string>n_ii</string></value>
+</data></array></value>
+</data></array></value>
+</param>
+</params>
+</methodResponse>`,
+		[]TicketAttachment{
+			{
+				Filename:    "Otsu.txt",
+				Description: "build log",
+				Size:        1024,
+				Time:        time.Date(1860, time.March, 24, 0, 0, 0, 0, time.UTC),
+				Author:      "n_ii",
+			},
+		},
+	}
+
+	c := NewTestClient(ticket_list_attachments, packArgs(test.ticket), test.reply)
+	res, err := c.Ticket.ListAttachments(test.ticket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, test.expected) {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketGetAttachment(t *testing.T) {
+	test := struct {
+		ticket   *int
+		filename *string
+		reply    string
+		expected []byte
+	}{
+		Int(7),
+		String("Otsu.txt"),
+		`<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><base64>5ruL6LOA</base64></value>
+</param>
+</params>
+</methodResponse>`,
+		[]byte("滋賀"),
+	}
+
+	c := NewTestClient(ticket_get_attachment, packArgs(test.ticket, test.filename), test.reply)
+	res, err := c.Ticket.GetAttachment(test.ticket, test.filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, test.expected) {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketPutAttachment(t *testing.T) {
+	test := struct {
+		ticket      *int
+		filename    *string
+		description *string
+		data        []byte
+		replace     *bool
+		reply       string
+		expected    string
+	}{
+		Int(7),
+		String("Shiga.txt"),
+		String("build log"),
+		[]byte("滋賀"),
+		Bool(true),
+		`<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><string>Shiga.txt</string></value>
+</param>
+</params>
+</methodResponse>`,
+		"Shiga.txt",
+	}
+
+	encData := base64String("5ruL6LOA")
+	c := NewTestClient(ticket_put_attachment, packArgs(test.ticket, test.filename, test.description, &encData, test.replace), test.reply)
+	res, err := c.Ticket.PutAttachment(test.ticket, test.filename, test.description, test.data, test.replace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != test.expected {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketDeleteAttachment(t *testing.T) {
+	test := struct {
+		ticket   *int
+		filename *string
+		reply    string
+		expected bool
+	}{
+		Int(7),
+		String("Shiga.txt"),
+		`<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><boolean>1</boolean></value>
+</param>
+</params>
+</methodResponse>`,
+		true,
+	}
+
+	c := NewTestClient(ticket_delete_attachment, packArgs(test.ticket, test.filename), test.reply)
+	res, err := c.Ticket.DeleteAttachment(test.ticket, test.filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != test.expected {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketGetRecentChanges(t *testing.T) {
+	test := struct {
+		since    *time.Time
+		reply    string
+		expected []int
+	}{
+		Time(time.Date(1860, time.March, 24, 0, 0, 0, 0, time.UTC)),
+		`<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><array><data>
+<value><int>7</int></value>
+<value><int>8</int></value>
+</data></array></value>
+</param>
+</params>
+</methodResponse>`,
+		[]int{7, 8},
+	}
+
+	c := NewTestClient(ticket_get_recent_changes, packArgs(test.since), test.reply)
+	res, err := c.Ticket.GetRecentChanges(test.since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, test.expected) {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketGetMany(t *testing.T) {
+	test := struct {
+		ids      []int
+		reply    string
+		expected []Ticket
+	}{
+		[]int{7, 7},
+		`<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><array><data>
+<value><array><data>
+<value><array><data>
+<value><int>7</int></value>
+<value><dateTime.iso8601>18600324T00:00:00</dateTime.iso8601></value>
+<value><dateTime.iso8601>18671109T00:00:00</dateTime.iso8601></value>
+<value><struct>
+<member>
+<name>summary</name>
+<value><string>sakuradamon</string></value>
+</member>
+</struct></value>
+</data></array></value>
+</data></array></value>
+<value><array><data>
+<value><array><data>
+<value><int>7</int></value>
+<value><dateTime.iso8601>18600324T00:00:00</dateTime.iso8601></value>
+<value><dateTime.iso8601>18671109T00:00:00</dateTime.iso8601></value>
+<value><struct>
+</struct></value>
+</data></array></value>
+</data></array></value>
+</data></array></value>
+</param>
+</params>
+</methodResponse>`,
+		[]Ticket{
+			{
+				ID:          7,
+				TimeCreated: time.Date(1860, time.March, 24, 0, 0, 0, 0, time.UTC),
+				TimeChanged: time.Date(1867, time.November, 9, 0, 0, 0, 0, time.UTC),
+				Attributes:  map[string]interface{}{"summary": "sakuradamon"},
+			},
+			{
+				ID:          7,
+				TimeCreated: time.Date(1860, time.March, 24, 0, 0, 0, 0, time.UTC),
+				TimeChanged: time.Date(1867, time.November, 9, 0, 0, 0, 0, time.UTC),
+				Attributes:  map[string]interface{}{},
+			},
+		},
+	}
+
+	params := []multicallParam{
+		{MethodName: ticket_get, Params: []interface{}{7}},
+		{MethodName: ticket_get, Params: []interface{}{7}},
+	}
+	c := NewTestClient(system_multicall, packArgs(&params), test.reply)
+	res, err := c.Ticket.GetMany(test.ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, test.expected) {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
@@ -0,0 +1,121 @@
+package tracrpc
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	ticket_version_get_all string = "ticket.version.getAll"
+	ticket_version_get     string = "ticket.version.get"
+	ticket_version_delete  string = "ticket.version.delete"
+	ticket_version_create  string = "ticket.version.create"
+	ticket_version_update  string = "ticket.version.update"
+)
+
+// TicketVersionService represents ticket version API service.
+type TicketVersionService struct {
+	rpc RpcClient
+}
+
+// TicketVersion represents the version returned by ticket.version.get.
+// Time is zero if not set.
+type TicketVersion struct {
+	Name        string
+	Time        time.Time
+	Description string
+}
+
+// TicketVersionAttributes represents attributes of ticket.version.create, ticket.version.update.
+// Nil attributes are left unchanged.
+type TicketVersionAttributes struct {
+	Name        *string    `xmlrpc:"name,omitempty"`
+	Time        *time.Time `xmlrpc:"time,omitempty"`
+	Description *string    `xmlrpc:"description,omitempty"`
+}
+
+// newTicketVersionService creates new TicketVersionService instance.
+func newTicketVersionService(rpc RpcClient) (*TicketVersionService, error) {
+	if rpc == nil {
+		return nil, errors.New("rpc client cannot be nil")
+	}
+
+	return &TicketVersionService{
+		rpc: rpc,
+	}, nil
+}
+
+// GetAll calls ticket.version.getAll.
+func (t *TicketVersionService) GetAll() ([]string, error) {
+	var reply []string
+	if err := t.rpc.Call(ticket_version_get_all, nil, &reply); err != nil {
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+// Get calls ticket.version.get.
+// NOTE: The server returns 0 instead of dateTime for the unset time.
+func (t *TicketVersionService) Get(name *string) (TicketVersion, error) {
+	args := packArgs(name)
+	var rawReply map[string]interface{}
+	if err := t.rpc.Call(ticket_version_get, args, &rawReply); err != nil {
+		return TicketVersion{}, err
+	}
+
+	var reply TicketVersion
+	if isNil(rawReply["name"]) {
+		// do nothing
+	} else if name, ok := rawReply["name"].(string); ok {
+		reply.Name = name
+	} else {
+		return TicketVersion{}, fmt.Errorf("%s: unexpected Name type. got=%v", ticket_version_get, rawReply["name"])
+	}
+	if when, ok := rawReply["time"].(time.Time); ok {
+		reply.Time = when
+	}
+	if isNil(rawReply["description"]) {
+		// do nothing
+	} else if description, ok := rawReply["description"].(string); ok {
+		reply.Description = description
+	} else {
+		return TicketVersion{}, fmt.Errorf("%s: unexpected Description type. got=%v", ticket_version_get, rawReply["description"])
+	}
+
+	return reply, nil
+}
+
+// Delete calls ticket.version.delete.
+func (t *TicketVersionService) Delete(name *string) (int, error) {
+	args := packArgs(name)
+	var reply int
+	if err := t.rpc.Call(ticket_version_delete, args, &reply); err != nil {
+		return 0, err
+	}
+
+	return reply, nil
+}
+
+// Create calls ticket.version.create.
+func (t *TicketVersionService) Create(name *string, attributes TicketVersionAttributes) (int, error) {
+	args := packArgs(name, &attributes)
+	var reply int
+	if err := t.rpc.Call(ticket_version_create, args, &reply); err != nil {
+		return 0, err
+	}
+
+	return reply, nil
+}
+
+// Update calls ticket.version.update.
+func (t *TicketVersionService) Update(name *string, attributes TicketVersionAttributes) (int, error) {
+	args := packArgs(name, &attributes)
+	var reply int
+	if err := t.rpc.Call(ticket_version_update, args, &reply); err != nil {
+		return 0, err
+	}
+
+	return reply, nil
+}
@@ -0,0 +1,184 @@
+package tracrpc
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewTicketVersionService(t *testing.T) {
+	tests := []struct {
+		name      string
+		rpcClient RpcClient
+		wantErr   bool
+	}{
+		{
+			name:      "OK",
+			rpcClient: &RpcClientMock{},
+			wantErr:   false,
+		},
+		{
+			name:      "NG",
+			rpcClient: nil,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTicketVersionService(tt.rpcClient)
+			if err != nil {
+				if tt.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestTicketVersionGetAll(t *testing.T) {
+	test := struct {
+		reply    string
+		expected []string
+	}{
+		`<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><array><data>
+<value><string>version1</string></value>
+<value><string>version2</string></value>
+</data></array></value>
+</param>
+</params>
+</methodResponse>`,
+		[]string{"version1", "version2"},
+	}
+
+	c := NewTestClient(ticket_version_get_all, nil, test.reply)
+	res, err := c.Ticket.Version.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, test.expected) {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketVersionGet(t *testing.T) {
+	test := struct {
+		name     *string
+		reply    string
+		expected TicketVersion
+	}{
+		String("version1"),
+		`<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><struct>
+<member>
+<name>time</name>
+<value><dateTime.iso8601>18671109T00:00:00</dateTime.iso8601></value>
+</member>
+<member>
+<name>name</name>
+<value><string>version1</string></value>
+</member>
+<member>
+<name>description</name>
+<value><string>taisei hokan</string></value>
+</member>
+</struct></value>
+</param>
+</params>
+</methodResponse>`,
+		TicketVersion{
+			Name:        "version1",
+			Time:        time.Date(1867, time.November, 9, 0, 0, 0, 0, time.UTC),
+			Description: "taisei hokan",
+		},
+	}
+
+	c := NewTestClient(ticket_version_get, packArgs(test.name), test.reply)
+	res, err := c.Ticket.Version.Get(test.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != test.expected {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketVersionCreate(t *testing.T) {
+	test := struct {
+		name       *string
+		attributes TicketVersionAttributes
+		reply      string
+		expected   int
+	}{
+		String("version3"),
+		TicketVersionAttributes{
+			Time:        Time(time.Date(1867, time.November, 9, 0, 0, 0, 0, time.UTC)),
+			Description: String("taisei hokan"),
+		},
+		intReply,
+		0,
+	}
+
+	c := NewTestClient(ticket_version_create, packArgs(test.name, &test.attributes), test.reply)
+	res, err := c.Ticket.Version.Create(test.name, test.attributes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != test.expected {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketVersionUpdate(t *testing.T) {
+	test := struct {
+		name       *string
+		attributes TicketVersionAttributes
+		reply      string
+		expected   int
+	}{
+		String("version3"),
+		TicketVersionAttributes{
+			Description: String("meiji"),
+		},
+		intReply,
+		0,
+	}
+
+	c := NewTestClient(ticket_version_update, packArgs(test.name, &test.attributes), test.reply)
+	res, err := c.Ticket.Version.Update(test.name, test.attributes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != test.expected {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestTicketVersionDelete(t *testing.T) {
+	test := struct {
+		name     *string
+		reply    string
+		expected int
+	}{
+		String("version3"),
+		intReply,
+		0,
+	}
+
+	c := NewTestClient(ticket_version_delete, packArgs(test.name), test.reply)
+	res, err := c.Ticket.Version.Delete(test.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != test.expected {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
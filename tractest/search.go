@@ -0,0 +1,131 @@
+package tractest
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// searchFilters are the filters returned by search.getSearchFilters.
+var searchFilters = [][]string{
+	{"ticket", "Tickets"},
+	{"wiki", "Wiki"},
+}
+
+// registerSearchMethods registers search.* methods.
+func registerSearchMethods(methods map[string]method) {
+	methods["search.getSearchFilters"] = method{
+		handler:   (*Server).getSearchFilters,
+		signature: []string{"array"},
+		help:      "Retrieve a list of search filters with each element in the form (name, description).",
+	}
+	methods["search.performSearch"] = method{
+		handler:   (*Server).performSearch,
+		signature: []string{"array", "string", "array"},
+		help:      "Perform a search using the given filters. Defaults to all if not provided. Results are returned as a list of tuples in the form (href, title, date, author, excerpt).",
+	}
+}
+
+// searchResult represents an element of the reply of search.performSearch.
+type searchResult struct {
+	href    string
+	title   string
+	date    time.Time
+	author  string
+	excerpt string
+}
+
+// getSearchFilters handles search.getSearchFilters.
+func (s *Server) getSearchFilters(c *call) (interface{}, error) {
+	return searchFilters, nil
+}
+
+// performSearch handles search.performSearch.
+// All the terms in the query must appear in the page or the ticket, case-insensitively.
+// All the filters are used if none is given.
+func (s *Server) performSearch(c *call) (interface{}, error) {
+	if err := c.requireParams(1); err != nil {
+		return nil, err
+	}
+	query, err := c.stringParam(0, "")
+	if err != nil {
+		return nil, err
+	}
+	filters, err := c.stringsParam(1)
+	if err != nil {
+		return nil, err
+	}
+	terms := strings.Fields(strings.ToLower(query))
+	enabled := func(name string) bool {
+		if len(filters) == 0 {
+			return true
+		}
+		for _, filter := range filters {
+			if filter == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	var results []searchResult
+	if enabled("wiki") {
+		for name, versions := range s.pages {
+			latest := versions[len(versions)-1]
+			if matchTerms(terms, name, latest.text) {
+				results = append(results, searchResult{
+					href:    "/wiki/" + name,
+					title:   name,
+					date:    latest.time,
+					author:  latest.author,
+					excerpt: excerpt(latest.text),
+				})
+			}
+		}
+	}
+	if enabled("ticket") {
+		for id, t := range s.tickets {
+			if matchTerms(terms, t.attributes["summary"], t.attributes["description"], t.attributes["keywords"]) {
+				results = append(results, searchResult{
+					href:    "/ticket/" + strconv.Itoa(id),
+					title:   "#" + strconv.Itoa(id) + ": " + t.attributes["summary"],
+					date:    t.created,
+					author:  t.attributes["reporter"],
+					excerpt: excerpt(t.attributes["description"]),
+				})
+			}
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].href < results[j].href })
+
+	reply := make([][]interface{}, 0, len(results))
+	for _, r := range results {
+		reply = append(reply, []interface{}{r.href, r.title, r.date, r.author, r.excerpt})
+	}
+
+	return reply, nil
+}
+
+// matchTerms checks all the terms appear in the texts.
+func matchTerms(terms []string, texts ...string) bool {
+	joined := strings.ToLower(strings.Join(texts, "\n"))
+	for _, term := range terms {
+		if !strings.Contains(joined, term) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// excerpt returns the beginning of the text.
+func excerpt(text string) string {
+	const max = 80
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	if len(runes) > max {
+		return string(runes[:max]) + "..."
+	}
+
+	return string(runes)
+}
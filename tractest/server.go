@@ -0,0 +1,321 @@
+// Package tractest provides a fake Trac RPC server for tests.
+//
+// The server implements the wiki, search, system and ticket XML-RPC methods
+// against an in-memory store, so that tools built on tracrpc can be tested end to end:
+//
+//	srv := tractest.NewServer()
+//	defer srv.Close()
+//	srv.AddPage("WikiStart", "= Welcome =", "admin", "")
+//	client, _ := tracrpc.NewClient(srv.URL+"/rpc", nil)
+package tractest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/f-velka/tracrpc/internal/xmlrpcwire"
+)
+
+// Fault codes returned by the server, the same as the Trac RPC plugin.
+const (
+	FaultGeneric          = 1
+	FaultPermissionDenied = 403
+	FaultNotFound         = 404
+	FaultMethodNotFound   = -32601
+)
+
+// anonymous is the author of the calls without Basic authentication.
+const anonymous = "anonymous"
+
+// Server represents the fake Trac RPC server.
+// It accepts XML-RPC calls at any path, e.g. "/rpc" and "/login/rpc".
+type Server struct {
+	*httptest.Server
+
+	// Now returns the current time, which is recorded as the time of the changes.
+	// It can be replaced before the calls to make the times deterministic.
+	Now func() time.Time
+
+	mu              sync.Mutex
+	pages           map[string][]*pageVersion
+	wikiAttachments map[string][]*attachment
+	tickets         map[int]*ticket
+	lastTicketID    int
+	methods         map[string]method
+}
+
+// method represents the handler of an RPC method.
+type method struct {
+	handler   func(s *Server, c *call) (interface{}, error)
+	signature []string
+	help      string
+}
+
+// call represents an RPC call made to the server.
+type call struct {
+	methodName string
+	params     []interface{}
+	author     string
+}
+
+// NewServer creates and starts new Server. The caller should call Close when finished.
+func NewServer() *Server {
+	s := newServer()
+	s.Server = httptest.NewServer(s)
+
+	return s
+}
+
+// NewUnstartedServer creates new Server but doesn't start it.
+// The caller should call Start or StartTLS, and Close when finished.
+func NewUnstartedServer() *Server {
+	s := newServer()
+	s.Server = httptest.NewUnstartedServer(s)
+
+	return s
+}
+
+// newServer creates new Server without the HTTP server.
+func newServer() *Server {
+	s := &Server{
+		Now: func() time.Time {
+			return time.Now()
+		},
+		pages:           make(map[string][]*pageVersion),
+		wikiAttachments: make(map[string][]*attachment),
+		tickets:         make(map[int]*ticket),
+	}
+	s.methods = make(map[string]method)
+	registerSystemMethods(s.methods)
+	registerSearchMethods(s.methods)
+	registerWikiMethods(s.methods)
+	registerTicketMethods(s.methods)
+
+	return s
+}
+
+// ServeHTTP handles the XML-RPC call.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "XML-RPC calls must be POST", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	methodName, params, err := xmlrpcwire.DecodeMethodCall(body)
+	if err != nil {
+		writeResponse(w, &xmlrpcwire.Fault{Code: FaultGeneric, String: err.Error()})
+		return
+	}
+	author := anonymous
+	if username, _, ok := req.BasicAuth(); ok {
+		author = username
+	}
+
+	s.mu.Lock()
+	reply, err := s.dispatch(&call{methodName: methodName, params: params, author: author})
+	s.mu.Unlock()
+	if err != nil {
+		writeResponse(w, toFault(err))
+		return
+	}
+	writeResponse(w, reply)
+}
+
+// dispatch calls the handler of the method.
+func (s *Server) dispatch(c *call) (interface{}, error) {
+	m, ok := s.methods[c.methodName]
+	if !ok {
+		return nil, &xmlrpcwire.Fault{
+			Code:   FaultMethodNotFound,
+			String: fmt.Sprintf(`RPC method "%s" not found`, c.methodName),
+		}
+	}
+
+	return m.handler(s, c)
+}
+
+// now returns the current time truncated to seconds, which is the precision of XML-RPC.
+func (s *Server) now() time.Time {
+	return s.Now().UTC().Truncate(time.Second)
+}
+
+// methodNames returns the names of the methods in order.
+func (s *Server) methodNames() []string {
+	names := make([]string, 0, len(s.methods))
+	for name := range s.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// writeResponse writes the reply as methodResponse.
+func writeResponse(w http.ResponseWriter, reply interface{}) {
+	data, err := xmlrpcwire.EncodeMethodResponse(reply)
+	if err != nil {
+		data = xmlrpcwire.EncodeFault(FaultGeneric, err.Error())
+	}
+	w.Header().Set("Content-Type", "text/xml")
+	w.Write(data)
+}
+
+// toFault converts err into the fault.
+func toFault(err error) *xmlrpcwire.Fault {
+	if fault, ok := err.(*xmlrpcwire.Fault); ok {
+		return fault
+	}
+
+	return &xmlrpcwire.Fault{Code: FaultGeneric, String: err.Error()}
+}
+
+// notFound creates the fault of the missing resource.
+func notFound(format string, a ...interface{}) error {
+	return &xmlrpcwire.Fault{Code: FaultNotFound, String: fmt.Sprintf(format, a...)}
+}
+
+// param returns the i-th param, or nil if omitted.
+func (c *call) param(i int) interface{} {
+	if i < len(c.params) {
+		return c.params[i]
+	}
+
+	return nil
+}
+
+// requireParams checks the number of params in the same message as Python,
+// which is mapped to tracrpc.ErrInvalidArgs.
+func (c *call) requireParams(min int) error {
+	if len(c.params) < min {
+		return fmt.Errorf("%s() takes at least %d arguments (%d given)", c.methodName, min, len(c.params))
+	}
+
+	return nil
+}
+
+// stringParam returns the i-th param as string, or def if omitted.
+func (c *call) stringParam(i int, def string) (string, error) {
+	switch v := c.param(i).(type) {
+	case nil:
+		return def, nil
+	case string:
+		return v, nil
+	}
+
+	return "", c.typeError(i, "string")
+}
+
+// intParam returns the i-th param as int, or def if omitted.
+func (c *call) intParam(i int, def int) (int, error) {
+	switch v := c.param(i).(type) {
+	case nil:
+		return def, nil
+	case int64:
+		return int(v), nil
+	}
+
+	return 0, c.typeError(i, "int")
+}
+
+// boolParam returns the i-th param as bool, or def if omitted.
+func (c *call) boolParam(i int, def bool) (bool, error) {
+	switch v := c.param(i).(type) {
+	case nil:
+		return def, nil
+	case bool:
+		return v, nil
+	case int64:
+		return v != 0, nil
+	}
+
+	return false, c.typeError(i, "boolean")
+}
+
+// timeParam returns the i-th param as time.Time, or def if omitted.
+func (c *call) timeParam(i int, def time.Time) (time.Time, error) {
+	switch v := c.param(i).(type) {
+	case nil:
+		return def, nil
+	case time.Time:
+		return v, nil
+	}
+
+	return time.Time{}, c.typeError(i, "dateTime.iso8601")
+}
+
+// bytesParam returns the i-th param as base64-decoded bytes.
+func (c *call) bytesParam(i int) ([]byte, error) {
+	switch v := c.param(i).(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return v, nil
+	}
+
+	return nil, c.typeError(i, "base64")
+}
+
+// structParam returns the i-th param as struct, or an empty one if omitted.
+func (c *call) structParam(i int) (map[string]interface{}, error) {
+	switch v := c.param(i).(type) {
+	case nil:
+		return map[string]interface{}{}, nil
+	case map[string]interface{}:
+		return v, nil
+	}
+
+	return nil, c.typeError(i, "struct")
+}
+
+// stringsParam returns the i-th param as array of strings, or nil if omitted.
+func (c *call) stringsParam(i int) ([]string, error) {
+	switch v := c.param(i).(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		strs := make([]string, 0, len(v))
+		for _, elem := range v {
+			str, ok := elem.(string)
+			if !ok {
+				return nil, c.typeError(i, "array of string")
+			}
+			strs = append(strs, str)
+		}
+		return strs, nil
+	}
+
+	return nil, c.typeError(i, "array")
+}
+
+// typeError creates the error of the param type.
+func (c *call) typeError(i int, expected string) error {
+	return fmt.Errorf("%s(): argument %d must be %s, not %T", c.methodName, i+1, expected, c.param(i))
+}
+
+// stringValue converts the attribute value sent by the client into string as Trac stores.
+func stringValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	}
+
+	return strings.TrimSpace(fmt.Sprint(v))
+}
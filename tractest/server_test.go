@@ -0,0 +1,137 @@
+package tractest_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/f-velka/tracrpc"
+	"github.com/f-velka/tracrpc/tractest"
+)
+
+func newTestClient(t *testing.T) (*tractest.Server, *tracrpc.Client) {
+	t.Helper()
+	srv := tractest.NewServer()
+	t.Cleanup(srv.Close)
+	now := time.Date(2021, time.April, 1, 9, 0, 0, 0, time.UTC)
+	srv.Now = func() time.Time { return now }
+
+	c, err := tracrpc.NewClient(srv.URL+"/login/rpc", nil, tracrpc.WithBasicAuth("admin", "admin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return srv, c
+}
+
+func TestWiki(t *testing.T) {
+	srv, c := newTestClient(t)
+	srv.AddPage("WikiStart", "= Welcome =", "trac", "")
+
+	if _, err := c.Wiki.PutPage(tracrpc.String("WikiStart"), tracrpc.String("= Welcome to Shiga ="), tracrpc.PutPageAttributes{Comment: tracrpc.String("shiga")}); err != nil {
+		t.Fatal(err)
+	}
+	res1, err := c.Wiki.GetPage(tracrpc.String("WikiStart"), tracrpc.Int(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res1 != "= Welcome =" {
+		t.Fatalf("unexpected result. expected=%v, got=%v", "= Welcome =", res1)
+	}
+	res2, err := c.Wiki.GetPageInfo(tracrpc.String("WikiStart"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := tracrpc.PageInfo{
+		Name:         "WikiStart",
+		LastModified: srv.Now(),
+		Author:       "admin",
+		Version:      2,
+		Comment:      "shiga",
+	}
+	if !reflect.DeepEqual(res2, expected) {
+		t.Fatalf("unexpected result. expected=%v, got=%v", expected, res2)
+	}
+
+	data := []byte("biwako")
+	filename, err := c.Wiki.PutAttachmentEx(tracrpc.String("WikiStart"), tracrpc.String("lake.txt"), tracrpc.String("lake"), data, tracrpc.Bool(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res3, err := c.Wiki.GetAttachment(tracrpc.String("WikiStart/" + filename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(res3, data) {
+		t.Fatalf("unexpected result. expected=%v, got=%v", data, res3)
+	}
+
+	_, err = c.Wiki.GetPage(tracrpc.String("Kyoto"), nil)
+	if !errors.Is(err, tracrpc.ErrNotFound) {
+		t.Fatalf("unexpected error. expected=%v, got=%v", tracrpc.ErrNotFound, err)
+	}
+}
+
+func TestTicket(t *testing.T) {
+	_, c := newTestClient(t)
+
+	id, err := c.Ticket.Create(tracrpc.String("sakuradamon"), tracrpc.String("ouch"), map[string]interface{}{"owner": "ii"}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Ticket.UpdateWithAction(&id, tracrpc.String("done"), "resolve", map[string]string{"action_resolve_resolve_resolution": "wontfix"}, nil, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	res1, err := c.Ticket.Get(&id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res1.Attributes["status"] != "closed" || res1.Attributes["resolution"] != "wontfix" {
+		t.Fatalf("unexpected attributes. got=%v", res1.Attributes)
+	}
+
+	res2, err := c.Ticket.ChangeLog(&id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sets := tracrpc.GroupTicketChanges(res2)
+	if len(sets) != 1 || sets[0].Author != "admin" || sets[0].Comment() != "done" {
+		t.Fatalf("unexpected change sets. got=%v", sets)
+	}
+
+	res3, err := c.Ticket.Query(tracrpc.NewTicketQuery().Is("status", "closed").Contains("summary", "sakura"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res3, []int{id}) {
+		t.Fatalf("unexpected result. expected=%v, got=%v", []int{id}, res3)
+	}
+	res4, err := c.Ticket.Query(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res4) != 0 {
+		t.Fatalf("unexpected result. expected=%v, got=%v", []int{}, res4)
+	}
+}
+
+func TestMulticall(t *testing.T) {
+	srv, c := newTestClient(t)
+	srv.AddPage("Shiga", "biwako", "trac", "")
+
+	results, err := c.System.Multicall(
+		func(c *tracrpc.Client) (interface{}, error) { return c.Wiki.GetPage(tracrpc.String("Shiga"), nil) },
+		func(c *tracrpc.Client) (interface{}, error) { return c.Wiki.GetPage(tracrpc.String("Kyoto"), nil) },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Value != "biwako" {
+		t.Fatalf("unexpected result. expected=%v, got=%v", "biwako", results[0].Value)
+	}
+	if !errors.Is(results[1].Err, tracrpc.ErrNotFound) {
+		t.Fatalf("unexpected error. expected=%v, got=%v", tracrpc.ErrNotFound, results[1].Err)
+	}
+}
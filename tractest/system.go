@@ -0,0 +1,129 @@
+package tractest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// apiVersion is the version returned by system.getAPIVersion, the same as the Trac RPC plugin 1.1.x.
+var apiVersion = []int{1, 1, 8}
+
+// registerSystemMethods registers system.* methods.
+func registerSystemMethods(methods map[string]method) {
+	methods["system.multicall"] = method{
+		handler:   (*Server).multicall,
+		signature: []string{"array", "array"},
+		help:      "Takes an array of RPC calls encoded as structs of the form (in a Pythonish notation here): {'methodName': string, 'params': array}.",
+	}
+	methods["system.listMethods"] = method{
+		handler:   (*Server).listMethods,
+		signature: []string{"array"},
+		help:      "This method returns a list of strings, one for each (non-system) method supported by the RPC server.",
+	}
+	methods["system.methodHelp"] = method{
+		handler:   (*Server).methodHelp,
+		signature: []string{"string", "string"},
+		help:      "This method takes one parameter, the name of a method implemented by the RPC server. It returns a documentation string describing the use of that method.",
+	}
+	methods["system.methodSignature"] = method{
+		handler:   (*Server).methodSignature,
+		signature: []string{"array", "string"},
+		help:      "This method takes one parameter, the name of a method implemented by the RPC server. It returns an array of possible signatures for this method.",
+	}
+	methods["system.getAPIVersion"] = method{
+		handler:   (*Server).getAPIVersion,
+		signature: []string{"array"},
+		help:      "Returns a list with three elements. First element is the epoch (0=Trac 0.10, 1=Trac 0.11 or higher). Second element is the major version number, third is the minor.",
+	}
+}
+
+// multicall handles system.multicall.
+// Each result is the array containing the value or the fault struct.
+func (s *Server) multicall(c *call) (interface{}, error) {
+	if err := c.requireParams(1); err != nil {
+		return nil, err
+	}
+	calls, ok := c.param(0).([]interface{})
+	if !ok {
+		return nil, c.typeError(0, "array")
+	}
+
+	results := make([]interface{}, 0, len(calls))
+	for _, elem := range calls {
+		reply, err := s.multicallOne(c, elem)
+		if err != nil {
+			fault := toFault(err)
+			results = append(results, map[string]interface{}{
+				"faultCode":   fault.Code,
+				"faultString": fault.String,
+			})
+			continue
+		}
+		results = append(results, []interface{}{reply})
+	}
+
+	return results, nil
+}
+
+// multicallOne calls a method batched in system.multicall.
+func (s *Server) multicallOne(c *call, elem interface{}) (interface{}, error) {
+	batched, ok := elem.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("system.multicall: call must be struct, not %T", elem)
+	}
+	methodName, _ := batched["methodName"].(string)
+	if methodName == "system.multicall" {
+		return nil, fmt.Errorf("system.multicall: recursive system.multicall is forbidden")
+	}
+	params, _ := batched["params"].([]interface{})
+
+	return s.dispatch(&call{methodName: methodName, params: params, author: c.author})
+}
+
+// listMethods handles system.listMethods.
+func (s *Server) listMethods(c *call) (interface{}, error) {
+	return s.methodNames(), nil
+}
+
+// methodHelp handles system.methodHelp.
+func (s *Server) methodHelp(c *call) (interface{}, error) {
+	m, err := s.lookupMethod(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.help, nil
+}
+
+// methodSignature handles system.methodSignature.
+// The signature is returned as the comma separated types, the return type first.
+func (s *Server) methodSignature(c *call) (interface{}, error) {
+	m, err := s.lookupMethod(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{strings.Join(m.signature, ",")}, nil
+}
+
+// getAPIVersion handles system.getAPIVersion.
+func (s *Server) getAPIVersion(c *call) (interface{}, error) {
+	return apiVersion, nil
+}
+
+// lookupMethod finds the method named by the first param.
+func (s *Server) lookupMethod(c *call) (method, error) {
+	if err := c.requireParams(1); err != nil {
+		return method{}, err
+	}
+	name, err := c.stringParam(0, "")
+	if err != nil {
+		return method{}, err
+	}
+	m, ok := s.methods[name]
+	if !ok {
+		return method{}, notFound(`RPC method "%s" not found`, name)
+	}
+
+	return m, nil
+}
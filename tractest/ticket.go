@@ -0,0 +1,816 @@
+package tractest
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ticket represents a ticket in the store.
+type ticket struct {
+	id          int
+	created     time.Time
+	changed     time.Time
+	attributes  map[string]string
+	changes     []*ticketChange
+	attachments []*attachment
+}
+
+// ticketChange represents an entry of the changelog.
+type ticketChange struct {
+	time      time.Time
+	author    string
+	field     string
+	oldValue  string
+	newValue  string
+	permanent bool
+}
+
+// ticketField represents a field returned by ticket.getTicketFields.
+type ticketField struct {
+	name     string
+	label    string
+	typ      string
+	options  []string
+	value    string
+	optional bool
+}
+
+// ticketFields are the fields of the default Trac configuration.
+var ticketFields = []ticketField{
+	{name: "summary", label: "Summary", typ: "text"},
+	{name: "reporter", label: "Reporter", typ: "text"},
+	{name: "owner", label: "Owner", typ: "text"},
+	{name: "description", label: "Description", typ: "textarea"},
+	{name: "type", label: "Type", typ: "select", options: []string{"defect", "enhancement", "task"}, value: "defect"},
+	{name: "status", label: "Status", typ: "select", options: []string{"accepted", "assigned", "closed", "new", "reopened"}},
+	{name: "priority", label: "Priority", typ: "select", options: []string{"blocker", "critical", "major", "minor", "trivial"}, value: "major"},
+	{name: "milestone", label: "Milestone", typ: "select", options: []string{"milestone1", "milestone2", "milestone3", "milestone4"}, optional: true},
+	{name: "component", label: "Component", typ: "select", options: []string{"component1", "component2"}},
+	{name: "version", label: "Version", typ: "select", options: []string{"1.0", "2.0"}, optional: true},
+	{name: "resolution", label: "Resolution", typ: "radio", options: []string{"fixed", "invalid", "wontfix", "duplicate", "worksforme"}},
+	{name: "keywords", label: "Keywords", typ: "text"},
+	{name: "cc", label: "Cc", typ: "text"},
+	{name: "time", label: "Created", typ: "time"},
+	{name: "changetime", label: "Modified", typ: "time"},
+}
+
+// resolutions are the options of the resolve action.
+var resolutions = []string{"fixed", "invalid", "wontfix", "duplicate", "worksforme"}
+
+// AddTicket creates a ticket with the attributes, and returns the id.
+// The status is "new" unless given.
+func (s *Server) AddTicket(summary, description, reporter string, attributes map[string]string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	merged := map[string]string{"reporter": reporter}
+	for name, value := range attributes {
+		merged[name] = value
+	}
+
+	return s.createTicket(summary, description, merged, s.now())
+}
+
+// AddTicketAttachment attaches the file to the ticket, replacing the existing one.
+// It returns false if the ticket doesn't exist.
+func (s *Server) AddTicketAttachment(id int, filename, description, author string, data []byte) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tickets[id]
+	if !ok {
+		return false
+	}
+	s.attachToTicket(t, &attachment{
+		filename:    filename,
+		description: description,
+		author:      author,
+		time:        s.now(),
+		data:        data,
+	}, true)
+
+	return true
+}
+
+// createTicket creates a ticket with the default values of the fields.
+func (s *Server) createTicket(summary, description string, attributes map[string]string, when time.Time) int {
+	s.lastTicketID++
+	t := &ticket{
+		id:         s.lastTicketID,
+		created:    when,
+		changed:    when,
+		attributes: make(map[string]string),
+	}
+	for _, field := range ticketFields {
+		if field.typ != "time" {
+			t.attributes[field.name] = field.value
+		}
+	}
+	t.attributes["status"] = "new"
+	for name, value := range attributes {
+		t.attributes[name] = value
+	}
+	t.attributes["summary"] = summary
+	t.attributes["description"] = description
+	s.tickets[t.id] = t
+
+	return t.id
+}
+
+// attachToTicket attaches the file and records it in the changelog.
+func (s *Server) attachToTicket(t *ticket, a *attachment, replace bool) {
+	t.attachments = putAttachment(t.attachments, a, replace)
+	t.changes = append(t.changes, &ticketChange{
+		time:      a.time,
+		author:    a.author,
+		field:     "attachment",
+		newValue:  a.filename,
+		permanent: false,
+	})
+}
+
+// registerTicketMethods registers ticket.* methods.
+func registerTicketMethods(methods map[string]method) {
+	methods["ticket.query"] = method{
+		handler:   (*Server).queryTickets,
+		signature: []string{"array", "string"},
+		help:      "Perform a ticket query, returning a list of ticket ID's.",
+	}
+	methods["ticket.getRecentChanges"] = method{
+		handler:   (*Server).getRecentTicketChanges,
+		signature: []string{"array", "dateTime.iso8601"},
+		help:      "Returns a list of IDs of tickets that have changed since timestamp.",
+	}
+	methods["ticket.getAvailableActions"] = method{
+		handler:   (*Server).getAvailableActions,
+		signature: []string{"array", "int"},
+		help:      "Deprecated - will be removed. Replaced by `getActions()`.",
+	}
+	methods["ticket.getActions"] = method{
+		handler:   (*Server).getActions,
+		signature: []string{"array", "int"},
+		help:      "Returns the actions that can be performed on the ticket as a list of `[action, label, hints, [input_fields]]` elements.",
+	}
+	methods["ticket.get"] = method{
+		handler:   (*Server).getTicket,
+		signature: []string{"array", "int"},
+		help:      "Fetch a ticket. Returns [id, time_created, time_changed, attributes].",
+	}
+	methods["ticket.create"] = method{
+		handler:   (*Server).createTicketMethod,
+		signature: []string{"int", "string", "string", "struct", "boolean", "dateTime.iso8601"},
+		help:      "Create a new ticket, returning the ticket ID.",
+	}
+	methods["ticket.update"] = method{
+		handler:   (*Server).updateTicket,
+		signature: []string{"array", "int", "string", "struct", "boolean", "string", "dateTime.iso8601"},
+		help:      "Update a ticket, returning the new ticket in the same form as get().",
+	}
+	methods["ticket.delete"] = method{
+		handler:   (*Server).deleteTicket,
+		signature: []string{"int", "int"},
+		help:      "Delete ticket with the given id.",
+	}
+	methods["ticket.changeLog"] = method{
+		handler:   (*Server).changeLog,
+		signature: []string{"struct", "int", "int"},
+		help:      "Return the changelog as a list of tuples of the form (time, author, field, oldvalue, newvalue, permanent).",
+	}
+	methods["ticket.listAttachments"] = method{
+		handler:   (*Server).listTicketAttachments,
+		signature: []string{"array", "int"},
+		help:      "Lists attachments for a given ticket. Returns (filename, description, size, time, author) for each attachment.",
+	}
+	methods["ticket.getAttachment"] = method{
+		handler:   (*Server).getTicketAttachment,
+		signature: []string{"base64", "int", "string"},
+		help:      "returns the content of an attachment.",
+	}
+	methods["ticket.putAttachment"] = method{
+		handler:   (*Server).putTicketAttachment,
+		signature: []string{"string", "int", "string", "string", "base64", "boolean"},
+		help:      "Add an attachment, optionally (and defaulting to) overwriting an existing one. Returns filename.",
+	}
+	methods["ticket.deleteAttachment"] = method{
+		handler:   (*Server).deleteTicketAttachment,
+		signature: []string{"boolean", "int", "string"},
+		help:      "Delete an attachment.",
+	}
+	methods["ticket.getTicketFields"] = method{
+		handler:   (*Server).getTicketFields,
+		signature: []string{"array"},
+		help:      "Return a list of all ticket fields fields.",
+	}
+}
+
+// queryTickets handles ticket.query. The default query is "status!=closed".
+// The results are ordered by id unless "order" is given, and paged by "max" (default 100) and "page".
+func (s *Server) queryTickets(c *call) (interface{}, error) {
+	qstr, err := c.stringParam(0, "status!=closed")
+	if err != nil {
+		return nil, err
+	}
+	q, err := parseQuery(qstr)
+	if err != nil {
+		return nil, err
+	}
+
+	tickets := make([]*ticket, 0, len(s.tickets))
+	for _, t := range s.tickets {
+		if q.match(t) {
+			tickets = append(tickets, t)
+		}
+	}
+	sort.Slice(tickets, func(i, j int) bool {
+		a, b := tickets[i], tickets[j]
+		if q.order != "id" && a.attributes[q.order] != b.attributes[q.order] {
+			return (a.attributes[q.order] < b.attributes[q.order]) != q.desc
+		}
+		return (a.id < b.id) != (q.desc && q.order == "id")
+	})
+	if q.max > 0 {
+		start := (q.page - 1) * q.max
+		if start > len(tickets) {
+			start = len(tickets)
+		}
+		end := start + q.max
+		if end > len(tickets) {
+			end = len(tickets)
+		}
+		tickets = tickets[start:end]
+	}
+
+	ids := make([]int, 0, len(tickets))
+	for _, t := range tickets {
+		ids = append(ids, t.id)
+	}
+
+	return ids, nil
+}
+
+// getRecentTicketChanges handles ticket.getRecentChanges.
+func (s *Server) getRecentTicketChanges(c *call) (interface{}, error) {
+	if err := c.requireParams(1); err != nil {
+		return nil, err
+	}
+	since, err := c.timeParam(0, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []int{}
+	for _, t := range s.sortedTickets() {
+		if !t.changed.Before(since) {
+			ids = append(ids, t.id)
+		}
+	}
+
+	return ids, nil
+}
+
+// getAvailableActions handles ticket.getAvailableActions.
+func (s *Server) getAvailableActions(c *call) (interface{}, error) {
+	t, err := s.lookupTicket(c)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, action := range ticketActions(t) {
+		names = append(names, action[0].(string))
+	}
+
+	return names, nil
+}
+
+// getActions handles ticket.getActions.
+func (s *Server) getActions(c *call) (interface{}, error) {
+	t, err := s.lookupTicket(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return ticketActions(t), nil
+}
+
+// getTicket handles ticket.get.
+func (s *Server) getTicket(c *call) (interface{}, error) {
+	t, err := s.lookupTicket(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return ticketReply(t), nil
+}
+
+// createTicketMethod handles ticket.create.
+// The reporter is the authenticated user unless given in the attributes.
+func (s *Server) createTicketMethod(c *call) (interface{}, error) {
+	if err := c.requireParams(2); err != nil {
+		return nil, err
+	}
+	summary, err := c.stringParam(0, "")
+	if err != nil {
+		return nil, err
+	}
+	description, err := c.stringParam(1, "")
+	if err != nil {
+		return nil, err
+	}
+	rawAttributes, err := c.structParam(2)
+	if err != nil {
+		return nil, err
+	}
+	when, err := c.timeParam(4, s.now())
+	if err != nil {
+		return nil, err
+	}
+	if summary == "" {
+		return nil, fmt.Errorf("Tickets must contain a summary.")
+	}
+
+	attributes := map[string]string{"reporter": c.author}
+	for name, value := range rawAttributes {
+		attributes[name] = stringValue(value)
+	}
+
+	return s.createTicket(summary, description, attributes, when), nil
+}
+
+// updateTicket handles ticket.update.
+// The "action" attribute applies the workflow of the default Trac configuration,
+// and "_ts" is checked against the time the ticket was changed.
+func (s *Server) updateTicket(c *call) (interface{}, error) {
+	t, err := s.lookupTicket(c)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.requireParams(2); err != nil {
+		return nil, err
+	}
+	comment, err := c.stringParam(1, "")
+	if err != nil {
+		return nil, err
+	}
+	rawAttributes, err := c.structParam(2)
+	if err != nil {
+		return nil, err
+	}
+	author, err := c.stringParam(4, "")
+	if err != nil {
+		return nil, err
+	}
+	when, err := c.timeParam(5, s.now())
+	if err != nil {
+		return nil, err
+	}
+	if author == "" {
+		author = c.author
+	}
+
+	attributes := make(map[string]string, len(rawAttributes))
+	for name, value := range rawAttributes {
+		attributes[name] = stringValue(value)
+	}
+	if ts, ok := attributes["_ts"]; ok && ts != timestamp(t.changed) {
+		return nil, fmt.Errorf("Sorry, can not save your changes. This ticket has been modified by someone else since you started")
+	}
+	if err := applyTicketAction(t, attributes, author); err != nil {
+		return nil, err
+	}
+
+	changes := []*ticketChange{}
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := attributes[name]
+		if name == "_ts" || name == "action" || strings.HasPrefix(name, "action_") || t.attributes[name] == value {
+			continue
+		}
+		changes = append(changes, &ticketChange{
+			time:      when,
+			author:    author,
+			field:     name,
+			oldValue:  t.attributes[name],
+			newValue:  value,
+			permanent: true,
+		})
+		t.attributes[name] = value
+	}
+	if comment != "" || len(changes) > 0 {
+		changes = append(changes, &ticketChange{
+			time:      when,
+			author:    author,
+			field:     "comment",
+			oldValue:  strconv.Itoa(countComments(t) + 1),
+			newValue:  comment,
+			permanent: true,
+		})
+		t.changes = append(t.changes, changes...)
+		t.changed = when
+	}
+
+	return ticketReply(t), nil
+}
+
+// deleteTicket handles ticket.delete.
+func (s *Server) deleteTicket(c *call) (interface{}, error) {
+	t, err := s.lookupTicket(c)
+	if err != nil {
+		return nil, err
+	}
+	delete(s.tickets, t.id)
+
+	return 0, nil
+}
+
+// changeLog handles ticket.changeLog.
+func (s *Server) changeLog(c *call) (interface{}, error) {
+	t, err := s.lookupTicket(c)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([][]interface{}, 0, len(t.changes))
+	for _, change := range t.changes {
+		permanent := 0
+		if change.permanent {
+			permanent = 1
+		}
+		entries = append(entries, []interface{}{change.time, change.author, change.field, change.oldValue, change.newValue, permanent})
+	}
+
+	return entries, nil
+}
+
+// listTicketAttachments handles ticket.listAttachments.
+func (s *Server) listTicketAttachments(c *call) (interface{}, error) {
+	t, err := s.lookupTicket(c)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := make([][]interface{}, 0, len(t.attachments))
+	for _, a := range t.attachments {
+		attachments = append(attachments, []interface{}{a.filename, a.description, len(a.data), a.time, a.author})
+	}
+
+	return attachments, nil
+}
+
+// getTicketAttachment handles ticket.getAttachment.
+func (s *Server) getTicketAttachment(c *call) (interface{}, error) {
+	t, filename, err := s.lookupTicketAttachment(c)
+	if err != nil {
+		return nil, err
+	}
+	a := findAttachment(t.attachments, filename)
+	if a == nil {
+		return nil, notFound(`Attachment "ticket:%d/%s" does not exist`, t.id, filename)
+	}
+
+	return a.data, nil
+}
+
+// putTicketAttachment handles ticket.putAttachment.
+func (s *Server) putTicketAttachment(c *call) (interface{}, error) {
+	if err := c.requireParams(4); err != nil {
+		return nil, err
+	}
+	t, filename, err := s.lookupTicketAttachment(c)
+	if err != nil {
+		return nil, err
+	}
+	description, err := c.stringParam(2, "")
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.bytesParam(3)
+	if err != nil {
+		return nil, err
+	}
+	replace, err := c.boolParam(4, true)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &attachment{
+		filename:    filename,
+		description: description,
+		author:      c.author,
+		time:        s.now(),
+		data:        data,
+	}
+	s.attachToTicket(t, a, replace)
+
+	return a.filename, nil
+}
+
+// deleteTicketAttachment handles ticket.deleteAttachment.
+func (s *Server) deleteTicketAttachment(c *call) (interface{}, error) {
+	t, filename, err := s.lookupTicketAttachment(c)
+	if err != nil {
+		return nil, err
+	}
+	attachments, ok := deleteAttachment(t.attachments, filename)
+	if !ok {
+		return nil, notFound(`Attachment "ticket:%d/%s" does not exist`, t.id, filename)
+	}
+	t.attachments = attachments
+
+	return true, nil
+}
+
+// getTicketFields handles ticket.getTicketFields.
+func (s *Server) getTicketFields(c *call) (interface{}, error) {
+	fields := make([]map[string]interface{}, 0, len(ticketFields))
+	for i, field := range ticketFields {
+		f := map[string]interface{}{
+			"name":   field.name,
+			"label":  field.label,
+			"type":   field.typ,
+			"value":  field.value,
+			"order":  i,
+			"custom": false,
+		}
+		if field.options != nil {
+			f["options"] = field.options
+			f["optional"] = field.optional
+		}
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+// lookupTicket finds the ticket whose id is the first param.
+func (s *Server) lookupTicket(c *call) (*ticket, error) {
+	if err := c.requireParams(1); err != nil {
+		return nil, err
+	}
+	id, err := c.intParam(0, 0)
+	if err != nil {
+		return nil, err
+	}
+	t, ok := s.tickets[id]
+	if !ok {
+		return nil, notFound("Ticket %d does not exist.", id)
+	}
+
+	return t, nil
+}
+
+// lookupTicketAttachment finds the ticket and the filename of the first and the second params.
+func (s *Server) lookupTicketAttachment(c *call) (*ticket, string, error) {
+	if err := c.requireParams(2); err != nil {
+		return nil, "", err
+	}
+	t, err := s.lookupTicket(c)
+	if err != nil {
+		return nil, "", err
+	}
+	filename, err := c.stringParam(1, "")
+	if err != nil {
+		return nil, "", err
+	}
+
+	return t, filename, nil
+}
+
+// sortedTickets returns the tickets in order of id.
+func (s *Server) sortedTickets() []*ticket {
+	tickets := make([]*ticket, 0, len(s.tickets))
+	for _, t := range s.tickets {
+		tickets = append(tickets, t)
+	}
+	sort.Slice(tickets, func(i, j int) bool { return tickets[i].id < tickets[j].id })
+
+	return tickets
+}
+
+// ticketReply returns the reply of ticket.get.
+func ticketReply(t *ticket) []interface{} {
+	attributes := make(map[string]interface{}, len(t.attributes)+1)
+	for name, value := range t.attributes {
+		attributes[name] = value
+	}
+	attributes["_ts"] = timestamp(t.changed)
+
+	return []interface{}{t.id, t.created, t.changed, attributes}
+}
+
+// timestamp returns the "_ts" attribute, the time the ticket was changed in microseconds.
+func timestamp(changed time.Time) string {
+	return strconv.FormatInt(changed.UnixNano()/int64(time.Microsecond), 10)
+}
+
+// countComments counts the comments in the changelog.
+func countComments(t *ticket) int {
+	n := 0
+	for _, change := range t.changes {
+		if change.field == "comment" {
+			n++
+		}
+	}
+
+	return n
+}
+
+// ticketActions returns the actions of the default workflow available in the status of the ticket.
+func ticketActions(t *ticket) [][]interface{} {
+	status := t.attributes["status"]
+	actions := [][]interface{}{
+		{"leave", "leave", "as " + status, []interface{}{}},
+	}
+	if status == "closed" {
+		return append(actions, []interface{}{
+			"reopen", "reopen", "The resolution will be deleted. Next status will be 'reopened'.", []interface{}{},
+		})
+	}
+
+	return append(actions,
+		[]interface{}{
+			"resolve", "resolve", "The resolution will be set. Next status will be 'closed'.",
+			[]interface{}{[]interface{}{"action_resolve_resolve_resolution", resolutions[0], resolutions}},
+		},
+		[]interface{}{
+			"reassign", "reassign", "The owner will be changed. Next status will be 'assigned'.",
+			[]interface{}{[]interface{}{"action_reassign_reassign_owner", t.attributes["owner"], []string{}}},
+		},
+		[]interface{}{
+			"accept", "accept", "The owner will be changed to the current user. Next status will be 'accepted'.", []interface{}{},
+		},
+	)
+}
+
+// applyTicketAction sets the attributes changed by the action.
+func applyTicketAction(t *ticket, attributes map[string]string, author string) error {
+	action, ok := attributes["action"]
+	if !ok {
+		return nil
+	}
+	for _, available := range ticketActions(t) {
+		if available[0] != action {
+			continue
+		}
+
+		switch action {
+		case "resolve":
+			resolution := attributes["action_resolve_resolve_resolution"]
+			if resolution == "" {
+				resolution = resolutions[0]
+			}
+			attributes["status"] = "closed"
+			attributes["resolution"] = resolution
+		case "reassign":
+			attributes["status"] = "assigned"
+			attributes["owner"] = attributes["action_reassign_reassign_owner"]
+		case "accept":
+			attributes["status"] = "accepted"
+			attributes["owner"] = author
+		case "reopen":
+			attributes["status"] = "reopened"
+			attributes["resolution"] = ""
+		}
+		return nil
+	}
+
+	return fmt.Errorf("invalid action \"%s\"", action)
+}
+
+// ticketQuery represents the parsed query of ticket.query.
+type ticketQuery struct {
+	conditions []queryCondition
+	order      string
+	desc       bool
+	max        int
+	page       int
+}
+
+// queryCondition represents a condition of the query.
+type queryCondition struct {
+	field  string
+	op     string
+	values []string
+}
+
+// queryOperators are the operators of the query, the longer first.
+var queryOperators = []string{"!~=", "!^=", "!$=", "!=", "~=", "^=", "$=", "="}
+
+// parseQuery parses the query string.
+func parseQuery(qstr string) (*ticketQuery, error) {
+	q := &ticketQuery{order: "id", max: 100, page: 1}
+	for _, term := range splitEscaped(qstr, '&') {
+		if term == "" {
+			continue
+		}
+		cond, err := parseCondition(term)
+		if err != nil {
+			return nil, err
+		}
+
+		var value string
+		if len(cond.values) > 0 {
+			value = cond.values[0]
+		}
+		switch cond.field {
+		case "order":
+			q.order = value
+		case "desc":
+			q.desc = value == "1"
+		case "max":
+			if q.max, err = strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("Query filter requires field and constraints separated by a \"=\": invalid max %s", value)
+			}
+		case "page":
+			if q.page, err = strconv.Atoi(value); err != nil || q.page < 1 {
+				return nil, fmt.Errorf("Page %s is invalid.", value)
+			}
+		case "col", "report", "format":
+		default:
+			q.conditions = append(q.conditions, cond)
+		}
+	}
+
+	return q, nil
+}
+
+// parseCondition parses "field<op>value|value".
+func parseCondition(term string) (queryCondition, error) {
+	i := strings.Index(term, "=")
+	if i < 0 {
+		return queryCondition{}, fmt.Errorf("Query filter requires field and constraints separated by a \"=\"")
+	}
+	field, op := term[:i], "="
+	for _, candidate := range queryOperators {
+		if strings.HasSuffix(term[:i+1], candidate) {
+			field, op = term[:i+1-len(candidate)], candidate
+			break
+		}
+	}
+
+	values := splitEscaped(term[i+1:], '|')
+	for j, value := range values {
+		values[j] = strings.NewReplacer(`\&`, "&", `\|`, "|").Replace(value)
+	}
+
+	return queryCondition{field: field, op: op, values: values}, nil
+}
+
+// splitEscaped splits s by sep which is not escaped with a backslash.
+func splitEscaped(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == sep {
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+// match checks the ticket meets all the conditions.
+func (q *ticketQuery) match(t *ticket) bool {
+	for _, cond := range q.conditions {
+		value := t.attributes[cond.field]
+		if cond.field == "id" {
+			value = strconv.Itoa(t.id)
+		}
+		if !cond.match(value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// match checks the value meets the condition. Any of the values is enough for the positive operators.
+func (cond queryCondition) match(value string) bool {
+	negative := strings.HasPrefix(cond.op, "!")
+	compare := map[string]func(string, string) bool{
+		"=":  func(v, c string) bool { return v == c },
+		"~=": strings.Contains,
+		"^=": strings.HasPrefix,
+		"$=": strings.HasSuffix,
+	}[strings.TrimPrefix(cond.op, "!")]
+
+	for _, c := range cond.values {
+		if compare(value, c) {
+			return !negative
+		}
+	}
+
+	return negative
+}
@@ -0,0 +1,580 @@
+package tractest
+
+import (
+	"errors"
+	"html"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pageVersion represents a version of a wiki page.
+type pageVersion struct {
+	version  int
+	text     string
+	author   string
+	comment  string
+	time     time.Time
+	readonly bool
+}
+
+// attachment represents a file attached to a wiki page or a ticket.
+type attachment struct {
+	filename    string
+	description string
+	author      string
+	time        time.Time
+	data        []byte
+}
+
+// AddPage adds a new version of the wiki page, and returns the version number.
+func (s *Server) AddPage(name, text, author, comment string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.putPage(name, text, author, comment, false, s.now())
+}
+
+// AddWikiAttachment attaches the file to the wiki page, replacing the existing one.
+func (s *Server) AddWikiAttachment(page, filename, description, author string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.wikiAttachments[page] = putAttachment(s.wikiAttachments[page], &attachment{
+		filename:    filename,
+		description: description,
+		author:      author,
+		time:        s.now(),
+		data:        data,
+	}, true)
+}
+
+// Page returns the text of the latest version of the wiki page.
+func (s *Server) Page(name string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, ok := s.pages[name]
+	if !ok {
+		return "", false
+	}
+
+	return versions[len(versions)-1].text, true
+}
+
+// putPage saves a new version of the page.
+func (s *Server) putPage(name, text, author, comment string, readonly bool, when time.Time) int {
+	versions := s.pages[name]
+	version := 1
+	if len(versions) > 0 {
+		version = versions[len(versions)-1].version + 1
+	}
+	s.pages[name] = append(versions, &pageVersion{
+		version:  version,
+		text:     text,
+		author:   author,
+		comment:  comment,
+		time:     when,
+		readonly: readonly,
+	})
+
+	return version
+}
+
+// registerWikiMethods registers wiki.* methods.
+func registerWikiMethods(methods map[string]method) {
+	methods["wiki.getRecentChanges"] = method{
+		handler:   (*Server).getRecentWikiChanges,
+		signature: []string{"array", "dateTime.iso8601"},
+		help:      "Get list of changed pages since timestamp",
+	}
+	methods["wiki.getRPCVersionSupported"] = method{
+		handler:   (*Server).getRPCVersionSupported,
+		signature: []string{"int"},
+		help:      "Returns 2 with this version of the Trac API.",
+	}
+	methods["wiki.getPage"] = method{
+		handler:   (*Server).getPage,
+		signature: []string{"string", "string", "int"},
+		help:      "Get the raw Wiki text of page, latest version.",
+	}
+	methods["wiki.getPageVersion"] = method{
+		handler:   (*Server).getPage,
+		signature: []string{"string", "string", "int"},
+		help:      "Get the raw Wiki text of page, latest version.",
+	}
+	methods["wiki.getPageHTML"] = method{
+		handler:   (*Server).getPageHTML,
+		signature: []string{"string", "string", "int"},
+		help:      "Return latest version of page as rendered HTML, utf8 encoded.",
+	}
+	methods["wiki.getPageHTMLVersion"] = method{
+		handler:   (*Server).getPageHTML,
+		signature: []string{"string", "string", "int"},
+		help:      "Return latest version of page as rendered HTML, utf8 encoded.",
+	}
+	methods["wiki.getAllPages"] = method{
+		handler:   (*Server).getAllPages,
+		signature: []string{"array"},
+		help:      "Returns a list of all pages. The result is an array of utf8 pagenames.",
+	}
+	methods["wiki.getPageInfo"] = method{
+		handler:   (*Server).getPageInfo,
+		signature: []string{"struct", "string", "int"},
+		help:      "Returns information about the given page.",
+	}
+	methods["wiki.getPageInfoVersion"] = method{
+		handler:   (*Server).getPageInfo,
+		signature: []string{"struct", "string", "int"},
+		help:      "Returns information about the given page.",
+	}
+	methods["wiki.putPage"] = method{
+		handler:   (*Server).putPageMethod,
+		signature: []string{"boolean", "string", "string", "struct"},
+		help:      "writes the content of the page.",
+	}
+	methods["wiki.listAttachments"] = method{
+		handler:   (*Server).listWikiAttachments,
+		signature: []string{"array", "string"},
+		help:      "Lists attachments on a given page.",
+	}
+	methods["wiki.getAttachment"] = method{
+		handler:   (*Server).getWikiAttachment,
+		signature: []string{"base64", "string"},
+		help:      "returns the content of an attachment.",
+	}
+	methods["wiki.putAttachment"] = method{
+		handler:   (*Server).putWikiAttachment,
+		signature: []string{"boolean", "string", "base64"},
+		help:      "(over)writes an attachment. Returns True if successful.",
+	}
+	methods["wiki.putAttachmentEx"] = method{
+		handler:   (*Server).putWikiAttachmentEx,
+		signature: []string{"string", "string", "string", "string", "base64", "boolean"},
+		help:      "Attach a file to a Wiki page. Returns the (possibly transformed) filename of the attachment.",
+	}
+	methods["wiki.deletePage"] = method{
+		handler:   (*Server).deletePage,
+		signature: []string{"boolean", "string", "int"},
+		help:      "Delete a Wiki page (all versions) or a specific version by including an optional version number.",
+	}
+	methods["wiki.deleteAttachment"] = method{
+		handler:   (*Server).deleteWikiAttachment,
+		signature: []string{"boolean", "string"},
+		help:      "Delete attachment. Returns True if successful.",
+	}
+	methods["wiki.listLinks"] = method{
+		handler:   (*Server).listLinks,
+		signature: []string{"array", "string"},
+		help:      "''Not implemented''",
+	}
+	methods["wiki.wikiToHtml"] = method{
+		handler:   (*Server).wikiToHtml,
+		signature: []string{"string", "string"},
+		help:      "Render arbitrary Wiki text as HTML.",
+	}
+}
+
+// getRecentWikiChanges handles wiki.getRecentChanges.
+func (s *Server) getRecentWikiChanges(c *call) (interface{}, error) {
+	if err := c.requireParams(1); err != nil {
+		return nil, err
+	}
+	since, err := c.timeParam(0, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	infos := []map[string]interface{}{}
+	for _, name := range s.pageNames() {
+		versions := s.pages[name]
+		latest := versions[len(versions)-1]
+		if !latest.time.Before(since) {
+			infos = append(infos, pageInfo(name, latest))
+		}
+	}
+
+	return infos, nil
+}
+
+// getRPCVersionSupported handles wiki.getRPCVersionSupported.
+func (s *Server) getRPCVersionSupported(c *call) (interface{}, error) {
+	return 2, nil
+}
+
+// getPage handles wiki.getPage and wiki.getPageVersion.
+func (s *Server) getPage(c *call) (interface{}, error) {
+	_, v, err := s.lookupPageVersion(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.text, nil
+}
+
+// getPageHTML handles wiki.getPageHTML and wiki.getPageHTMLVersion.
+func (s *Server) getPageHTML(c *call) (interface{}, error) {
+	_, v, err := s.lookupPageVersion(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return renderHTML(v.text), nil
+}
+
+// getAllPages handles wiki.getAllPages.
+func (s *Server) getAllPages(c *call) (interface{}, error) {
+	return s.pageNames(), nil
+}
+
+// getPageInfo handles wiki.getPageInfo and wiki.getPageInfoVersion.
+func (s *Server) getPageInfo(c *call) (interface{}, error) {
+	name, v, err := s.lookupPageVersion(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return pageInfo(name, v), nil
+}
+
+// putPageMethod handles wiki.putPage.
+// The author in the attributes is used instead of the authenticated user if given.
+func (s *Server) putPageMethod(c *call) (interface{}, error) {
+	if err := c.requireParams(3); err != nil {
+		return nil, err
+	}
+	name, err := c.stringParam(0, "")
+	if err != nil {
+		return nil, err
+	}
+	text, err := c.stringParam(1, "")
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := c.structParam(2)
+	if err != nil {
+		return nil, err
+	}
+	author, _ := attributes["author"].(string)
+	if author == "" {
+		author = c.author
+	}
+	comment, _ := attributes["comment"].(string)
+	readonly, _ := attributes["readonly"].(bool)
+
+	if versions, ok := s.pages[name]; ok {
+		latest := versions[len(versions)-1]
+		if latest.text == text && latest.readonly == readonly {
+			return nil, errors.New("Page not modified")
+		}
+	}
+	s.putPage(name, text, author, comment, readonly, s.now())
+
+	return true, nil
+}
+
+// listWikiAttachments handles wiki.listAttachments.
+// The attachments are returned as the paths "pagename/filename".
+func (s *Server) listWikiAttachments(c *call) (interface{}, error) {
+	if err := c.requireParams(1); err != nil {
+		return nil, err
+	}
+	name, err := c.stringParam(0, "")
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{}
+	for _, a := range s.wikiAttachments[name] {
+		paths = append(paths, name+"/"+a.filename)
+	}
+
+	return paths, nil
+}
+
+// getWikiAttachment handles wiki.getAttachment.
+func (s *Server) getWikiAttachment(c *call) (interface{}, error) {
+	page, filename, err := attachmentPathParam(c)
+	if err != nil {
+		return nil, err
+	}
+	a := findAttachment(s.wikiAttachments[page], filename)
+	if a == nil {
+		return nil, notFound(`Attachment "%s" does not exist`, page+"/"+filename)
+	}
+
+	return a.data, nil
+}
+
+// putWikiAttachment handles wiki.putAttachment.
+func (s *Server) putWikiAttachment(c *call) (interface{}, error) {
+	if err := c.requireParams(2); err != nil {
+		return nil, err
+	}
+	page, filename, err := attachmentPathParam(c)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.bytesParam(1)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := s.pages[page]; !ok {
+		return nil, notFound(`Wiki page "%s" does not exist`, page)
+	}
+
+	s.wikiAttachments[page] = putAttachment(s.wikiAttachments[page], &attachment{
+		filename: filename,
+		author:   c.author,
+		time:     s.now(),
+		data:     data,
+	}, true)
+
+	return true, nil
+}
+
+// putWikiAttachmentEx handles wiki.putAttachmentEx.
+func (s *Server) putWikiAttachmentEx(c *call) (interface{}, error) {
+	if err := c.requireParams(4); err != nil {
+		return nil, err
+	}
+	page, err := c.stringParam(0, "")
+	if err != nil {
+		return nil, err
+	}
+	filename, err := c.stringParam(1, "")
+	if err != nil {
+		return nil, err
+	}
+	description, err := c.stringParam(2, "")
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.bytesParam(3)
+	if err != nil {
+		return nil, err
+	}
+	replace, err := c.boolParam(4, true)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := s.pages[page]; !ok {
+		return nil, notFound(`Wiki page "%s" does not exist`, page)
+	}
+
+	a := &attachment{
+		filename:    filename,
+		description: description,
+		author:      c.author,
+		time:        s.now(),
+		data:        data,
+	}
+	s.wikiAttachments[page] = putAttachment(s.wikiAttachments[page], a, replace)
+
+	return a.filename, nil
+}
+
+// deletePage handles wiki.deletePage.
+// Without the version, all the versions and the attachments are deleted.
+func (s *Server) deletePage(c *call) (interface{}, error) {
+	if err := c.requireParams(1); err != nil {
+		return nil, err
+	}
+	name, err := c.stringParam(0, "")
+	if err != nil {
+		return nil, err
+	}
+	version, err := c.intParam(1, 0)
+	if err != nil {
+		return nil, err
+	}
+	versions, ok := s.pages[name]
+	if !ok {
+		return nil, notFound(`Wiki page "%s" does not exist`, name)
+	}
+
+	if version == 0 {
+		delete(s.pages, name)
+		delete(s.wikiAttachments, name)
+		return true, nil
+	}
+	for i, v := range versions {
+		if v.version == version {
+			versions = append(versions[:i:i], versions[i+1:]...)
+			if len(versions) == 0 {
+				delete(s.pages, name)
+				delete(s.wikiAttachments, name)
+			} else {
+				s.pages[name] = versions
+			}
+			return true, nil
+		}
+	}
+
+	return nil, notFound(`Wiki page "%s" does not exist at version %d`, name, version)
+}
+
+// deleteWikiAttachment handles wiki.deleteAttachment.
+func (s *Server) deleteWikiAttachment(c *call) (interface{}, error) {
+	page, filename, err := attachmentPathParam(c)
+	if err != nil {
+		return nil, err
+	}
+	attachments, ok := deleteAttachment(s.wikiAttachments[page], filename)
+	if !ok {
+		return nil, notFound(`Attachment "%s" does not exist`, page+"/"+filename)
+	}
+	s.wikiAttachments[page] = attachments
+
+	return true, nil
+}
+
+// listLinks handles wiki.listLinks, which is not implemented by the Trac RPC plugin either.
+func (s *Server) listLinks(c *call) (interface{}, error) {
+	return []string{}, nil
+}
+
+// wikiToHtml handles wiki.wikiToHtml.
+func (s *Server) wikiToHtml(c *call) (interface{}, error) {
+	if err := c.requireParams(1); err != nil {
+		return nil, err
+	}
+	text, err := c.stringParam(0, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return renderHTML(text), nil
+}
+
+// lookupPageVersion finds the page version named by the first and the second params.
+// The latest version is returned if the version is omitted or 0.
+func (s *Server) lookupPageVersion(c *call) (string, *pageVersion, error) {
+	if err := c.requireParams(1); err != nil {
+		return "", nil, err
+	}
+	name, err := c.stringParam(0, "")
+	if err != nil {
+		return "", nil, err
+	}
+	version, err := c.intParam(1, 0)
+	if err != nil {
+		return "", nil, err
+	}
+	versions, ok := s.pages[name]
+	if !ok {
+		return "", nil, notFound(`Wiki page "%s" does not exist`, name)
+	}
+
+	if version == 0 {
+		return name, versions[len(versions)-1], nil
+	}
+	for _, v := range versions {
+		if v.version == version {
+			return name, v, nil
+		}
+	}
+
+	return "", nil, notFound(`Wiki page "%s" does not exist at version %d`, name, version)
+}
+
+// pageNames returns the names of the pages in order.
+func (s *Server) pageNames() []string {
+	names := make([]string, 0, len(s.pages))
+	for name := range s.pages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// pageInfo returns the struct of wiki.getPageInfo.
+func pageInfo(name string, v *pageVersion) map[string]interface{} {
+	return map[string]interface{}{
+		"name":         name,
+		"lastModified": v.time,
+		"author":       v.author,
+		"version":      v.version,
+		"comment":      v.comment,
+	}
+}
+
+// renderHTML renders the wiki text as HTML.
+// The text is not formatted but escaped in paragraphs.
+func renderHTML(text string) string {
+	var b strings.Builder
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		if strings.TrimSpace(paragraph) == "" {
+			continue
+		}
+		b.WriteString("<p>\n")
+		b.WriteString(html.EscapeString(strings.TrimSpace(paragraph)))
+		b.WriteString("\n</p>\n")
+	}
+
+	return b.String()
+}
+
+// attachmentPathParam splits the first param "pagename/filename" of the attachment path.
+func attachmentPathParam(c *call) (string, string, error) {
+	if err := c.requireParams(1); err != nil {
+		return "", "", err
+	}
+	p, err := c.stringParam(0, "")
+	if err != nil {
+		return "", "", err
+	}
+	i := strings.LastIndex(p, "/")
+	if i < 0 {
+		return "", "", notFound(`Attachment "%s" does not exist`, p)
+	}
+
+	return p[:i], p[i+1:], nil
+}
+
+// findAttachment finds the attachment by filename.
+func findAttachment(attachments []*attachment, filename string) *attachment {
+	for _, a := range attachments {
+		if a.filename == filename {
+			return a
+		}
+	}
+
+	return nil
+}
+
+// putAttachment adds the attachment. If the filename exists, the attachment is
+// replaced, or renamed to the unique one like "file.2.txt" unless replace.
+func putAttachment(attachments []*attachment, a *attachment, replace bool) []*attachment {
+	if existing := findAttachment(attachments, a.filename); existing != nil {
+		if replace {
+			*existing = *a
+			return attachments
+		}
+		ext := path.Ext(a.filename)
+		base := strings.TrimSuffix(a.filename, ext)
+		for i := 2; ; i++ {
+			filename := base + "." + strconv.Itoa(i) + ext
+			if findAttachment(attachments, filename) == nil {
+				a.filename = filename
+				break
+			}
+		}
+	}
+
+	return append(attachments, a)
+}
+
+// deleteAttachment removes the attachment by filename.
+func deleteAttachment(attachments []*attachment, filename string) ([]*attachment, bool) {
+	for i, a := range attachments {
+		if a.filename == filename {
+			return append(attachments[:i:i], attachments[i+1:]...), true
+		}
+	}
+
+	return attachments, false
+}
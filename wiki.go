@@ -4,6 +4,8 @@ import (
 	"encoding/base64"
 	"errors"
 	"time"
+
+	"github.com/f-velka/tracrpc/wikiconv"
 )
 
 const (
@@ -48,6 +50,10 @@ type PageInfo struct {
 	Comment      string    `xmlrpc:"comment"`
 }
 
+// Link represents a TracLink in a wiki page, such as wiki:WikiStart, #1 or [[Image(a.png)]].
+// The kinds are defined in the wikiconv package, e.g. wikiconv.LinkTicket.
+type Link = wikiconv.Link
+
 // newWikiService creates new WikiService instance.
 func newWikiService(rpc RpcClient) (*WikiService, error) {
 	if rpc == nil {
@@ -91,6 +97,20 @@ func (w *WikiService) GetPage(pagename *string, version *int) (string, error) {
 	return reply, nil
 }
 
+// GetPageMarkdown calls wiki.getPage and converts the text into Markdown.
+// The attachments in the text are resolved against the page unless opts sets another page.
+func (w *WikiService) GetPageMarkdown(pagename *string, version *int, opts ...wikiconv.Option) (string, error) {
+	text, err := w.GetPage(pagename, version)
+	if err != nil {
+		return "", err
+	}
+	if pagename != nil {
+		opts = append([]wikiconv.Option{wikiconv.WithPage(*pagename)}, opts...)
+	}
+
+	return wikiconv.ToMarkdown(text, opts...), nil
+}
+
 // GetPageVersion calls wiki.getPageVersion.
 func (w *WikiService) GetPageVersion(pagename *string, version *int) (string, error) {
 	args := packArgs(pagename, version)
@@ -161,12 +181,26 @@ func (w *WikiService) PutPage(pagename *string, content *string, attributes PutP
 	args := packArgs(pagename, content, &attributes)
 	var reply bool
 	if err := w.rpc.Call(wiki_put_page, args, &reply); err != nil {
-		return false, nil
+		return false, err
 	}
 
 	return reply, nil
 }
 
+// PutMarkdownPage converts the Markdown content into Trac WikiFormatting and calls wiki.putPage.
+// The relative links in the content are resolved against the page unless opts sets another page.
+func (w *WikiService) PutMarkdownPage(pagename *string, content *string, attributes PutPageAttributes, opts ...wikiconv.Option) (bool, error) {
+	if pagename != nil {
+		opts = append([]wikiconv.Option{wikiconv.WithPage(*pagename)}, opts...)
+	}
+	var text *string
+	if content != nil {
+		text = String(wikiconv.FromMarkdown(*content, opts...))
+	}
+
+	return w.PutPage(pagename, text, attributes)
+}
+
 // ListAttachments calls wiki.listAttachments.
 func (w *WikiService) ListAttachments(pagename *string) ([]string, error) {
 	args := packArgs(pagename)
@@ -241,17 +275,15 @@ func (w *WikiService) DeleteAttachment(path *string) (bool, error) {
 	return reply, nil
 }
 
-// ListLinks calls wiki.listLinks.
-// Unfortunately, this API is not implemented yet.
-func (w *WikiService) ListLinks(pagename *string) (bool, error) {
-	return false, errors.New("wiki.listLinks is not implemented")
-	// args := packArgs(pagename)
-	// var reply bool
-	// if err := w.rpc.Call(wiki_list_links, args, &reply); err != nil {
-	// 	return false, err
-	// }
-
-	// return reply, nil
+// ListLinks returns the TracLinks in the latest version of the page, in order of appearance.
+// Since wiki.listLinks is not implemented by the server, the links are extracted from the text of wiki.getPage.
+func (w *WikiService) ListLinks(pagename *string) ([]Link, error) {
+	text, err := w.GetPage(pagename, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return wikiconv.Links(text), nil
 }
 
 // WikiToHtml calls wiki.wikiToHtml.
@@ -0,0 +1,179 @@
+package tracrpc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/f-velka/tracrpc/internal/diff"
+)
+
+// diffContextLines is the number of the context lines of the diffs.
+const diffContextLines = 3
+
+// PageVersion represents a version of a wiki page with its text.
+type PageVersion struct {
+	PageInfo
+	Text string
+}
+
+// PageHistory represents an iterator over the versions of a wiki page, from the latest to the oldest.
+//
+//	history := client.Wiki.History(tracrpc.String("WikiStart"))
+//	for history.Next() {
+//		version := history.Version()
+//		...
+//	}
+//	if err := history.Err(); err != nil {
+//		...
+//	}
+type PageHistory struct {
+	wiki    *WikiService
+	name    string
+	next    int
+	current PageVersion
+	err     error
+}
+
+// History returns PageHistory of the page.
+// The versions are fetched by wiki.getPageInfoVersion and wiki.getPageVersion on demand.
+func (w *WikiService) History(pagename *string) *PageHistory {
+	h := &PageHistory{
+		wiki: w,
+		next: -1,
+	}
+	if pagename == nil {
+		h.err = errors.New("pagename cannot be nil")
+		return h
+	}
+	h.name = *pagename
+
+	return h
+}
+
+// Next fetches the next older version. It returns false when there are no more versions or an error occurs.
+func (h *PageHistory) Next() bool {
+	if h.err != nil {
+		return false
+	}
+	if h.next < 0 {
+		latest, err := h.wiki.GetPageInfo(String(h.name), nil)
+		if err != nil {
+			h.err = err
+			return false
+		}
+		h.next = latest.Version
+	}
+
+	for ; h.next > 0; h.next-- {
+		version, err := h.wiki.getPageVersion(h.name, h.next)
+		if errors.Is(err, ErrNotFound) {
+			// the version has been deleted.
+			continue
+		}
+		if err != nil {
+			h.err = err
+			return false
+		}
+		h.current = version
+		h.next--
+		return true
+	}
+
+	return false
+}
+
+// Version returns the version fetched by the last call of Next.
+func (h *PageHistory) Version() PageVersion {
+	return h.current
+}
+
+// Err returns the error occurred during the iteration.
+func (h *PageHistory) Err() error {
+	return h.err
+}
+
+// DiffPage returns the unified diff of the page between the versions.
+// If to is nil, the latest version is used. If from is nil, the version before to is used.
+// It returns an empty string if the texts are the same.
+func (w *WikiService) DiffPage(pagename *string, from *int, to *int) (string, error) {
+	if pagename == nil {
+		return "", errors.New("pagename cannot be nil")
+	}
+
+	var toVersion PageVersion
+	var err error
+	if to == nil {
+		info, err := w.GetPageInfo(pagename, nil)
+		if err != nil {
+			return "", err
+		}
+		toVersion, err = w.getPageVersion(*pagename, info.Version)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		toVersion, err = w.getPageVersion(*pagename, *to)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	fromVersion := PageVersion{PageInfo: PageInfo{Name: *pagename}}
+	if from != nil {
+		fromVersion, err = w.getPageVersion(*pagename, *from)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		// the older versions may have been deleted.
+		for version := toVersion.Version - 1; version > 0; version-- {
+			older, err := w.getPageVersion(*pagename, version)
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return "", err
+			}
+			fromVersion = older
+			break
+		}
+	}
+
+	return UnifiedDiff(fromVersion, toVersion), nil
+}
+
+// UnifiedDiff returns the unified diff between the versions of a page.
+// The headers contain the version, the time, the author and the comment of each version,
+// and the version 0 denotes the page which does not exist.
+func UnifiedDiff(from, to PageVersion) string {
+	return diff.Unified(diffHeader(from), diffHeader(to), from.Text, to.Text, diffContextLines)
+}
+
+// getPageVersion fetches the info and the text of the version.
+func (w *WikiService) getPageVersion(name string, version int) (PageVersion, error) {
+	info, err := w.GetPageInfoVersion(String(name), Int(version))
+	if err != nil {
+		return PageVersion{}, err
+	}
+	text, err := w.GetPageVersion(String(name), Int(version))
+	if err != nil {
+		return PageVersion{}, err
+	}
+
+	return PageVersion{
+		PageInfo: info,
+		Text:     text,
+	}, nil
+}
+
+// diffHeader formats the header line of the version, e.g. "WikiStart\tversion 2\t2021-03-03T09:00:00Z\tii\tfix typo".
+func diffHeader(v PageVersion) string {
+	if v.Version == 0 {
+		return v.Name + "\tversion 0"
+	}
+	comment := strings.Join(strings.Fields(v.Comment), " ")
+
+	return fmt.Sprintf("%s\tversion %d\t%s\t%s\t%s", v.Name, v.Version, v.LastModified.UTC().Format(time.RFC3339), v.Author, comment)
+}
@@ -0,0 +1,153 @@
+package tracrpc
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/f-velka/tracrpc/tractest"
+)
+
+// newHistoryTestClient creates the client of the server which has 4 versions of "Runbook" and the version 2 is deleted.
+func newHistoryTestClient(t *testing.T) (*Client, func()) {
+	srv := tractest.NewServer()
+	now := time.Date(2021, 3, 3, 9, 0, 0, 0, time.UTC)
+	srv.Now = func() time.Time {
+		now = now.Add(time.Hour)
+		return now
+	}
+	srv.AddPage("Runbook", "= Runbook =\nrestart\n", "ii", "create")
+	srv.AddPage("Runbook", "= Runbook =\nreboot\n", "ii", "")
+	srv.AddPage("Runbook", "= Runbook =\nrestart the server\n", "naosuke", "clarify\nthe step")
+	srv.AddPage("Runbook", "= Runbook =\nrestart the server\ncheck the logs\n", "ii", "add a step")
+
+	client, err := NewClient(srv.URL+"/rpc", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Wiki.DeletePage(String("Runbook"), Int(2)); err != nil {
+		t.Fatal(err)
+	}
+
+	return client, srv.Close
+}
+
+func TestPageHistory(t *testing.T) {
+	client, closeServer := newHistoryTestClient(t)
+	defer closeServer()
+
+	history := client.Wiki.History(String("Runbook"))
+	versions := []int{}
+	authors := []string{}
+	for history.Next() {
+		versions = append(versions, history.Version().Version)
+		authors = append(authors, history.Version().Author)
+	}
+	if err := history.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []int{4, 3, 1}; !reflect.DeepEqual(versions, expected) {
+		t.Fatalf("unexpected versions. expected=%v, got=%v", expected, versions)
+	}
+	if expected := []string{"ii", "naosuke", "ii"}; !reflect.DeepEqual(authors, expected) {
+		t.Fatalf("unexpected authors. expected=%v, got=%v", expected, authors)
+	}
+
+	history = client.Wiki.History(String("Kyoto"))
+	if history.Next() {
+		t.Fatalf("unexpected version. expected=none, got=%v", history.Version())
+	}
+	if err := history.Err(); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("unexpected error. expected=%v, got=%v", ErrNotFound, err)
+	}
+}
+
+func TestDiffPage(t *testing.T) {
+	client, closeServer := newHistoryTestClient(t)
+	defer closeServer()
+
+	tests := []struct {
+		name     string
+		from     *int
+		to       *int
+		expected string
+	}{
+		{
+			"Latest",
+			nil,
+			nil,
+			"--- Runbook\tversion 3\t2021-03-03T12:00:00Z\tnaosuke\tclarify the step\n" +
+				"+++ Runbook\tversion 4\t2021-03-03T13:00:00Z\tii\tadd a step\n" +
+				"@@ -1,2 +1,3 @@\n = Runbook =\n restart the server\n+check the logs\n",
+		},
+		{
+			"DeletedVersion",
+			nil,
+			Int(3),
+			"--- Runbook\tversion 1\t2021-03-03T10:00:00Z\tii\tcreate\n" +
+				"+++ Runbook\tversion 3\t2021-03-03T12:00:00Z\tnaosuke\tclarify the step\n" +
+				"@@ -1,2 +1,2 @@\n = Runbook =\n-restart\n+restart the server\n",
+		},
+		{
+			"First",
+			nil,
+			Int(1),
+			"--- Runbook\tversion 0\n" +
+				"+++ Runbook\tversion 1\t2021-03-03T10:00:00Z\tii\tcreate\n" +
+				"@@ -0,0 +1,2 @@\n+= Runbook =\n+restart\n",
+		},
+		{
+			"Same",
+			Int(4),
+			Int(4),
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.Wiki.DiffPage(String("Runbook"), tt.from, tt.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.expected {
+				t.Fatalf("unexpected result. expected=%q, got=%q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDiffPageOlderVersionsNotFound(t *testing.T) {
+	srv := tractest.NewServer()
+	defer srv.Close()
+	now := time.Date(2021, 3, 3, 9, 0, 0, 0, time.UTC)
+	srv.Now = func() time.Time {
+		now = now.Add(time.Hour)
+		return now
+	}
+	srv.AddPage("Runbook", "restart\n", "ii", "create")
+	srv.AddPage("Runbook", "reboot\n", "ii", "")
+	srv.AddPage("Runbook", "restart the server\n", "naosuke", "clarify")
+
+	client, err := NewClient(srv.URL+"/rpc", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, version := range []int{1, 2} {
+		if _, err := client.Wiki.DeletePage(String("Runbook"), Int(version)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := client.Wiki.DiffPage(String("Runbook"), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "--- Runbook\tversion 0\n" +
+		"+++ Runbook\tversion 3\t2021-03-03T12:00:00Z\tnaosuke\tclarify\n" +
+		"@@ -0,0 +1 @@\n+restart the server\n"
+	if got != expected {
+		t.Fatalf("unexpected result. expected=%q, got=%q", expected, got)
+	}
+}
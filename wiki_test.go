@@ -2,9 +2,12 @@ package tracrpc
 
 import (
 	"encoding/base64"
+	"errors"
 	"reflect"
 	"testing"
 	"time"
+
+	"github.com/f-velka/tracrpc/wikiconv"
 )
 
 func TestNewWikiService(t *testing.T) {
@@ -183,6 +186,37 @@ func TestGetPage(t *testing.T) {
 	}
 }
 
+func TestGetPageMarkdown(t *testing.T) {
+	test := struct {
+		pagename *string
+		version  *int
+		reply    string
+		expected string
+	}{
+		String("Shiga"),
+		nil,
+		`<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><string>= Shiga =
+'''Biwako''' [[Image(biwako.png)]]</string></value>
+</param>
+</params>
+</methodResponse>`,
+		"# Shiga\n\n**Biwako** ![biwako.png](/raw-attachment/wiki/Shiga/biwako.png)\n",
+	}
+
+	c := NewTestClient(wiki_get_page, packArgs(test.pagename, test.version), test.reply)
+	res, err := c.Wiki.GetPageMarkdown(test.pagename, test.version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != test.expected {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
 func TestGetPageVersion(t *testing.T) {
 	test := struct {
 		pagename *string
@@ -453,6 +487,81 @@ func TestPutPage(t *testing.T) {
 	}
 }
 
+func TestPutMarkdownPage(t *testing.T) {
+	test := struct {
+		pagename   *string
+		content    *string
+		text       *string
+		attributes PutPageAttributes
+		reply      string
+		expected   bool
+	}{
+		String("Dev/Start"),
+		String("# Start\n\nSee [Setup](Setup.md) with **WikiSync**."),
+		String("= Start =\n\nSee [wiki:Dev/Setup Setup] with '''!WikiSync'''.\n"),
+		PutPageAttributes{
+			Comment: String("markdown"),
+		},
+		`<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><boolean>1</boolean></value>
+</param>
+</params>
+</methodResponse>`,
+		true,
+	}
+
+	c := NewTestClient(wiki_put_page, packArgs(test.pagename, test.text, &test.attributes), test.reply)
+	res, err := c.Wiki.PutMarkdownPage(test.pagename, test.content, test.attributes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != test.expected {
+		t.Fatalf("unexpected result. expected=%v, got=%v", test.expected, res)
+	}
+}
+
+func TestPutMarkdownPageFault(t *testing.T) {
+	test := struct {
+		pagename *string
+		content  *string
+		text     *string
+		reply    string
+		expected error
+	}{
+		String("Dev/Start"),
+		String("# Start"),
+		String("= Start =\n"),
+		`<?xml version='1.0'?>
+<methodResponse>
+<fault>
+<value><struct>
+<member>
+<name>faultCode</name>
+<value><int>403</int></value>
+</member>
+<member>
+<name>faultString</name>
+<value><string>WIKI_MODIFY privileges are required to perform this operation on Dev/Start</string></value>
+</member>
+</struct></value>
+</fault>
+</methodResponse>`,
+		ErrPermissionDenied,
+	}
+
+	c := NewTestClient(wiki_put_page, packArgs(test.pagename, test.text, &PutPageAttributes{}), test.reply)
+	res, err := c.Wiki.PutMarkdownPage(test.pagename, test.content, PutPageAttributes{})
+	if !errors.Is(err, test.expected) {
+		t.Fatalf("unexpected error. expected=%v, got=%v", test.expected, err)
+	}
+	if res {
+		t.Fatalf("unexpected result. expected=%v, got=%v", false, res)
+	}
+}
+
 func TestListAttachments(t *testing.T) {
 	test := struct {
 		pagename *string
@@ -640,7 +749,42 @@ func TestDeleteAttachment(t *testing.T) {
 }
 
 func TestListLinks(t *testing.T) {
-	// this API is not implemented.
+	test := struct {
+		pagename *string
+		reply    string
+		expected []Link
+	}{
+		String("Shiga"),
+		`<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><string>See [wiki:Biwako the lake] and #1.
+{{{
+wiki:Ignored
+}}}
+[[Image(otsu.png)]]
+[wiki:"My Page" label] [milestone:'1.0 beta']</string></value>
+</param>
+</params>
+</methodResponse>`,
+		[]Link{
+			{Kind: wikiconv.LinkWiki, Target: "Biwako", Label: "the lake", Position: 4},
+			{Kind: wikiconv.LinkTicket, Target: "1", Label: "#1", Position: 31},
+			{Kind: wikiconv.LinkAttachment, Target: "otsu.png", Label: "otsu.png", Image: true, Position: 56},
+			{Kind: wikiconv.LinkWiki, Target: "My Page", Label: "label", Position: 76},
+			{Kind: wikiconv.LinkMilestone, Target: "1.0 beta", Label: "[milestone:'1.0 beta']", Position: 99},
+		},
+	}
+
+	c := NewTestClient(wiki_get_page, packArgs(test.pagename), test.reply)
+	res, err := c.Wiki.ListLinks(test.pagename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, test.expected) {
+		t.Fatalf("unexpected result. expected=%+v, got=%+v", test.expected, res)
+	}
 }
 
 func TestWikiToHtml(t *testing.T) {
@@ -0,0 +1,255 @@
+package wikiconv
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	codeSpanRegexp      = regexp.MustCompile(`^\{\{\{(.*?)\}\}\}`)
+	backtickRegexp      = regexp.MustCompile("^`([^`]*)`")
+	doubleBracketRegexp = regexp.MustCompile(`^\[\[(.+?)\]\]`)
+	bracketLinkRegexp   = regexp.MustCompile(`^\[([a-z]+:(?:"[^"]*"|'[^']*')|[^\[\]\s]+)(?:\s+([^\]]*))?\]`)
+	macroRegexp         = regexp.MustCompile(`^(\w+)(?:\((.*)\))?$`)
+	trailingPunctRegx   = regexp.MustCompile(`[.,;:!?'"]+$`)
+	quotedLinkRegexp    = regexp.MustCompile(`^[a-z]+:(?:"[^"]*"|'[^']*')$`)
+)
+
+// bareLinkRule represents a rule of the TracLinks without the brackets.
+type bareLinkRule struct {
+	re *regexp.Regexp
+	// boundary reports whether the match must not follow a letter or a digit.
+	boundary bool
+}
+
+// bareLinkRules are the rules of the URLs, the prefixed links, "#1", "r1", "{1}" and CamelCase in order.
+var bareLinkRules = []bareLinkRule{
+	{re: regexp.MustCompile(`^(?:[a-zA-Z][a-zA-Z0-9+.-]*://|mailto:)[^\s<>"\]\)|]+`), boundary: true},
+	{re: regexp.MustCompile(`^[a-z]+:(?:"[^"]*"|'[^']*'|[^\s\]\)\(,|]+)`), boundary: true},
+	{re: regexp.MustCompile(`^#\d+\b`), boundary: true},
+	{re: regexp.MustCompile(`^r\d+\b`), boundary: true},
+	{re: regexp.MustCompile(`^\{\d+\}`)},
+	{re: regexp.MustCompile(`^(?:[A-Z][a-z0-9]+){2,}(?:/(?:[A-Z][a-z0-9]+){2,})*\b`), boundary: true},
+}
+
+// Links returns the TracLinks in the text in order of appearance, with the byte offsets in Position.
+// The links in the code blocks and the code spans, the escaped links such as "!WikiStart" and the macros
+// other than [[Image]] are ignored, while the links in the blocks of wiki text such as "#!div" are included.
+func Links(text string) []Link {
+	links := []Link{}
+	// raw holds whether each of the nested {{{ }}} blocks is not wiki text.
+	raw := []bool{}
+	lines := strings.SplitAfter(text, "\n")
+	offset := 0
+	for i, line := range lines {
+		start := offset
+		offset += len(line)
+
+		trimmed := strings.TrimSpace(line)
+		inRaw := len(raw) > 0 && raw[len(raw)-1]
+		switch {
+		case isCodeBlockStart(trimmed):
+			processor := strings.TrimSpace(trimmed[3:])
+			if processor == "" && i+1 < len(lines) {
+				processor = strings.TrimSpace(lines[i+1])
+			}
+			raw = append(raw, inRaw || !isWikiProcessor(processor))
+			continue
+		case trimmed == "}}}" && len(raw) > 0:
+			raw = raw[:len(raw)-1]
+			continue
+		case inRaw:
+			continue
+		}
+
+		for _, link := range lineLinks(line) {
+			link.Position += start
+			links = append(links, link)
+		}
+	}
+
+	return links
+}
+
+// isWikiProcessor reports whether the processor line of the {{{ }}} block, e.g. "#!div class=note", is wiki text.
+func isWikiProcessor(line string) bool {
+	m := processorRegexp.FindStringSubmatch(line)
+	return m != nil && wikiProcessors[m[1]]
+}
+
+// lineLinks returns the TracLinks in the line, with the byte offsets in the line.
+func lineLinks(line string) []Link {
+	links := []Link{}
+	for i := 0; i < len(line); {
+		if line[i] == '!' {
+			// "!" escapes the link.
+			if _, n := linkAt(line, i+1); n > 0 {
+				i += 1 + n
+				continue
+			}
+		}
+		if link, n := linkAt(line, i); n > 0 {
+			if link.Kind != "" {
+				link.Position = i
+				links = append(links, link)
+			}
+			i += n
+			continue
+		}
+
+		_, size := utf8.DecodeRuneInString(line[i:])
+		i += size
+	}
+
+	return links
+}
+
+// linkAt parses the TracLink at i, and returns the length of the text consumed, or 0 if there is none.
+// The code spans and the macros are consumed without the link, i.e. the zero Link is returned.
+func linkAt(text string, i int) (Link, int) {
+	if i >= len(text) {
+		return Link{}, 0
+	}
+	rest := text[i:]
+	if m := codeSpanRegexp.FindString(rest); m != "" {
+		return Link{}, len(m)
+	}
+	if m := backtickRegexp.FindString(rest); m != "" {
+		return Link{}, len(m)
+	}
+	if m := doubleBracketRegexp.FindStringSubmatch(rest); m != nil {
+		if name, args, ok := parseMacro(m[1]); ok {
+			if name == "Image" {
+				return parseImage(args), len(m[0])
+			}
+			return Link{}, len(m[0])
+		}
+		if link, ok := parseDoubleBracketLink(m[1]); ok {
+			return link, len(m[0])
+		}
+	}
+	if m := bracketLinkRegexp.FindStringSubmatch(rest); m != nil {
+		if link, ok := parseBracketLink(m); ok {
+			return link, len(m[0])
+		}
+	}
+	for _, rule := range bareLinkRules {
+		if rule.boundary && isWordBefore(text, i) {
+			continue
+		}
+		m := rule.re.FindString(rest)
+		if m == "" {
+			continue
+		}
+		if link, n := parseBareLink(m); n > 0 {
+			return link, n
+		}
+	}
+
+	return Link{}, 0
+}
+
+// parseBareLink parses the match of bareLinkRules, and returns the length of the link,
+// which excludes the trailing punctuation, or 0 if it is not a link.
+func parseBareLink(text string) (Link, int) {
+	if !quotedLinkRegexp.MatchString(text) {
+		text = trailingPunctRegx.ReplaceAllString(text, "")
+	}
+	link, ok := parseLink(text)
+	if !ok {
+		return Link{}, 0
+	}
+	link.Label = text
+
+	return link, len(text)
+}
+
+// parseBracketLink parses the match of bracketLinkRegexp, e.g. "[wiki:WikiStart label]", "[wiki:"My Page" label]" or "[1234]".
+func parseBracketLink(m []string) (Link, bool) {
+	link, ok := parseLink(m[1])
+	if !ok {
+		return Link{}, false
+	}
+	link.Label = strings.TrimSpace(m[2])
+	if link.Label == "" {
+		link.Label = m[0]
+		if link.Kind == LinkWiki || link.Kind == LinkURL {
+			link.Label = link.Target
+		}
+	}
+
+	return link, true
+}
+
+// parseMacro parses the content of [[ ]] as a macro call, e.g. "Image(a.png)" or "BR".
+func parseMacro(inner string) (name, args string, ok bool) {
+	inner = strings.TrimSpace(inner)
+	m := macroRegexp.FindStringSubmatch(inner)
+	if m == nil || !(strings.Contains(inner, "(") || isMacroName(m[1])) {
+		return "", "", false
+	}
+
+	return m[1], m[2], true
+}
+
+// isMacroName reports whether the name is a macro rather than a page name.
+func isMacroName(name string) bool {
+	return name == "BR" || name == "Image" || hiddenMacros[name]
+}
+
+// parseDoubleBracketLink parses the content of [[ ]] as a link, e.g. "WikiStart|label" or "ticket:1".
+func parseDoubleBracketLink(inner string) (Link, bool) {
+	inner = strings.TrimSpace(inner)
+	spec, label := inner, ""
+	if i := strings.Index(inner, "|"); i >= 0 {
+		spec, label = strings.TrimSpace(inner[:i]), strings.TrimSpace(inner[i+1:])
+	}
+	link, ok := parseLink(spec)
+	if !ok {
+		if spec == "" || strings.Contains(spec, ":") {
+			return Link{}, false
+		}
+		link = Link{Kind: LinkWiki, Target: spec}
+	}
+	link.Label = label
+	if link.Label == "" {
+		link.Label = spec
+		if link.Kind == LinkWiki {
+			link.Label = link.Target
+		}
+	}
+
+	return link, true
+}
+
+// parseImage parses the args of [[Image]], e.g. "wiki:WikiStart:a.png, alt=logo", into the link to the image.
+func parseImage(args string) Link {
+	params := strings.Split(args, ",")
+	target := strings.TrimSpace(params[0])
+	alt := ""
+	for _, param := range params[1:] {
+		if param = strings.TrimSpace(param); strings.HasPrefix(param, "alt=") {
+			alt = strings.TrimPrefix(param, "alt=")
+		}
+	}
+
+	link := Link{Kind: LinkAttachment, Target: target, Image: true}
+	switch {
+	case urlRegexp.MatchString(target):
+		link.Kind = LinkURL
+	case strings.HasPrefix(target, "source:") || strings.HasPrefix(target, "browser:"):
+		link.Kind = LinkSource
+		link.Target = target[strings.Index(target, ":")+1:]
+	case strings.HasPrefix(target, "wiki:") || strings.HasPrefix(target, "ticket:"):
+		if i := strings.LastIndex(target, ":"); i > strings.Index(target, ":") {
+			link.Target, link.Parent = target[i+1:], target[:i]
+		}
+	}
+	link.Label = alt
+	if link.Label == "" {
+		link.Label = link.Target
+	}
+
+	return link
+}
@@ -0,0 +1,55 @@
+package wikiconv
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []Link
+	}{
+		{"Empty", "", []Link{}},
+		{"Prefixed", "wiki:Shiga, ticket:1 changeset:2 report:3 milestone:1.0 attachment:a.txt source:trunk/a.go", []Link{
+			{Kind: LinkWiki, Target: "Shiga", Label: "wiki:Shiga", Position: 0},
+			{Kind: LinkTicket, Target: "1", Label: "ticket:1", Position: 12},
+			{Kind: LinkChangeset, Target: "2", Label: "changeset:2", Position: 21},
+			{Kind: LinkReport, Target: "3", Label: "report:3", Position: 33},
+			{Kind: LinkMilestone, Target: "1.0", Label: "milestone:1.0", Position: 42},
+			{Kind: LinkAttachment, Target: "a.txt", Label: "attachment:a.txt", Position: 56},
+			{Kind: LinkSource, Target: "trunk/a.go", Label: "source:trunk/a.go", Position: 73},
+		}},
+		{"Shorthand", "#1 [2] r3 {4} WikiStart.", []Link{
+			{Kind: LinkTicket, Target: "1", Label: "#1", Position: 0},
+			{Kind: LinkChangeset, Target: "2", Label: "[2]", Position: 3},
+			{Kind: LinkChangeset, Target: "3", Label: "r3", Position: 7},
+			{Kind: LinkReport, Target: "4", Label: "{4}", Position: 10},
+			{Kind: LinkWiki, Target: "WikiStart", Label: "WikiStart", Position: 14},
+		}},
+		{"Brackets", "[wiki:Dev/Setup setup]\n[[Shiga|lake]] [[Image(wiki:Shiga:b.png)]] [http://example.com]", []Link{
+			{Kind: LinkWiki, Target: "Dev/Setup", Label: "setup", Position: 0},
+			{Kind: LinkWiki, Target: "Shiga", Label: "lake", Position: 23},
+			{Kind: LinkAttachment, Target: "b.png", Parent: "wiki:Shiga", Label: "b.png", Image: true, Position: 38},
+			{Kind: LinkURL, Target: "http://example.com", Label: "http://example.com", Position: 66},
+		}},
+		{"Quoted", "[wiki:\"My Page\" label] wiki:'Other Page'", []Link{
+			{Kind: LinkWiki, Target: "My Page", Label: "label", Position: 0},
+			{Kind: LinkWiki, Target: "Other Page", Label: "wiki:'Other Page'", Position: 23},
+		}},
+		{"Ignored", "!WikiStart {{{#1}}} `r2` [[PageOutline]] [[TicketQuery(id=3)]] iPhone\n{{{\nwiki:Code\n}}}\n{{{#!comment\n{{{\n#4\n}}}\n}}}", []Link{}},
+		{"WikiProcessor", "{{{\n#!div class=note\n#5\n}}}", []Link{
+			{Kind: LinkTicket, Target: "5", Label: "#5", Position: 21},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Links(tt.text)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Fatalf("unexpected result. expected=%+v, got=%+v", tt.expected, got)
+			}
+		})
+	}
+}
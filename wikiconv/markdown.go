@@ -0,0 +1,690 @@
+package wikiconv
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	headingRegexp    = regexp.MustCompile(`^\s*(={1,6})\s+(.+?)(?:\s+=+)?(?:\s+#\S+)?\s*$`)
+	ruleRegexp       = regexp.MustCompile(`^\s*-{4,}\s*$`)
+	tableRowRegexp   = regexp.MustCompile(`^\s*\|\|`)
+	bulletRegexp     = regexp.MustCompile(`^(\s*)([*-])\s+(.*)$`)
+	orderedRegexp    = regexp.MustCompile(`^(\s+)(\d+\.|[a-zA-Z]\.|[ivxIVX]+\.)\s+(.*)$`)
+	definitionRegexp = regexp.MustCompile(`^\s+(.+?)::(?:\s+(.*))?$`)
+	quoteRegexp      = regexp.MustCompile(`^\s{2,}\S`)
+	citationRegexp   = regexp.MustCompile(`^(>+)\s?(.*)$`)
+	blockStartRegexp = regexp.MustCompile(`^([#>+]|[-=*](\s|$))`)
+	orderedStartRegx = regexp.MustCompile(`^(\d+)([.)](?:\s|$))`)
+	processorRegexp  = regexp.MustCompile(`^#!(\S+)`)
+	mimeTypePrefix   = regexp.MustCompile(`^(?:text|application)/(?:x-)?`)
+)
+
+// inlineRule represents a rule of the inline markup.
+type inlineRule struct {
+	re *regexp.Regexp
+	// boundary reports whether the match must not follow a letter or a digit.
+	boundary bool
+	// convert converts the match, and returns the length of the text consumed, or 0 if it is not the markup.
+	convert func(c *markdownConverter, m []string) (string, int)
+}
+
+// inlineRules are tried in order at each position of the text.
+// They are initialized in init, since the rules convert the nested markup by themselves.
+var inlineRules []inlineRule
+
+func init() {
+	inlineRules = []inlineRule{
+		{re: codeSpanRegexp, convert: func(c *markdownConverter, m []string) (string, int) {
+			return codeSpan(m[1]), len(m[0])
+		}},
+		{re: backtickRegexp, convert: func(c *markdownConverter, m []string) (string, int) {
+			return codeSpan(m[1]), len(m[0])
+		}},
+		{re: doubleBracketRegexp, convert: (*markdownConverter).macroOrLink},
+		{re: bracketLinkRegexp, convert: (*markdownConverter).bracketLink},
+		{re: regexp.MustCompile(`(?s)^~~(.+?)~~`), convert: emphasis("~~", "~~")},
+		{re: regexp.MustCompile(`(?s)^__(.+?)__`), convert: emphasis("<u>", "</u>")},
+		{re: regexp.MustCompile(`^\^([^\n^]+?)\^`), convert: emphasis("<sup>", "</sup>")},
+		{re: regexp.MustCompile(`^,,([^\n]+?),,`), convert: emphasis("<sub>", "</sub>")},
+	}
+	for _, rule := range bareLinkRules {
+		inlineRules = append(inlineRules, inlineRule{re: rule.re, boundary: rule.boundary, convert: (*markdownConverter).bareLink})
+	}
+}
+
+// hiddenMacros are the macros which have no meaning in Markdown.
+var hiddenMacros = map[string]bool{
+	"PageOutline":  true,
+	"TOC":          true,
+	"TracGuideToc": true,
+}
+
+// wikiProcessors are the processors of {{{ }}} blocks whose content is wiki text.
+var wikiProcessors = map[string]bool{
+	"div":   true,
+	"span":  true,
+	"table": true,
+	"tr":    true,
+	"td":    true,
+	"th":    true,
+	"box":   true,
+	"wiki":  true,
+}
+
+// markdownConverter represents a conversion from Trac WikiFormatting to Markdown.
+type markdownConverter struct {
+	options options
+}
+
+// ToMarkdown converts Trac WikiFormatting into CommonMark.
+// The tables are converted into GFM tables, which most Markdown renderers support.
+func ToMarkdown(text string, opts ...Option) string {
+	c := &markdownConverter{options: newOptions(opts)}
+	md := c.convert(text)
+	if md == "" {
+		return ""
+	}
+
+	return md + "\n"
+}
+
+// convert converts the blocks of the text.
+func (c *markdownConverter) convert(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	blocks := []string{}
+	add := func(block string) {
+		if strings.TrimSpace(block) != "" {
+			blocks = append(blocks, block)
+		}
+	}
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			i++
+		case isCodeBlockStart(trimmed):
+			block, n := c.codeBlock(lines[i:])
+			add(block)
+			i += n
+		case headingRegexp.MatchString(line):
+			m := headingRegexp.FindStringSubmatch(line)
+			add(strings.Repeat("#", len(m[1])) + " " + c.inline(m[2]))
+			i++
+		case ruleRegexp.MatchString(line):
+			add("---")
+			i++
+		case tableRowRegexp.MatchString(line):
+			n := countLines(lines[i:], func(line string) bool {
+				return tableRowRegexp.MatchString(line)
+			})
+			add(c.table(lines[i : i+n]))
+			i += n
+		case isListItem(line):
+			n := 1 + countLines(lines[i+1:], func(line string) bool {
+				return isListItem(line) || (strings.TrimSpace(line) != "" && startsWithSpace(line) && !isCodeBlockStart(strings.TrimSpace(line)))
+			})
+			add(c.list(lines[i : i+n]))
+			i += n
+		case definitionRegexp.MatchString(line):
+			n := 1 + countLines(lines[i+1:], func(line string) bool {
+				return strings.TrimSpace(line) != "" && startsWithSpace(line) && !isListItem(line) && !isCodeBlockStart(strings.TrimSpace(line))
+			})
+			add(c.definitions(lines[i : i+n]))
+			i += n
+		case quoteRegexp.MatchString(line):
+			n := countLines(lines[i:], func(line string) bool {
+				return quoteRegexp.MatchString(line) && !isListItem(line) && !definitionRegexp.MatchString(line) && !isCodeBlockStart(strings.TrimSpace(line))
+			})
+			add(prefixLines(c.paragraph(lines[i:i+n]), "> "))
+			i += n
+		case citationRegexp.MatchString(line):
+			n := countLines(lines[i:], func(line string) bool {
+				return citationRegexp.MatchString(line)
+			})
+			add(c.citation(lines[i : i+n]))
+			i += n
+		default:
+			n := 1 + countLines(lines[i+1:], func(line string) bool {
+				return !isBlockStart(line)
+			})
+			add(c.paragraph(lines[i : i+n]))
+			i += n
+		}
+	}
+
+	return strings.Join(blocks, "\n\n")
+}
+
+// codeBlock converts the {{{ }}} block at the beginning of the lines, and returns the number of the lines.
+func (c *markdownConverter) codeBlock(lines []string) (string, int) {
+	body := []string{}
+	if first := strings.TrimSpace(lines[0])[3:]; first != "" {
+		body = append(body, first)
+	}
+	n := 1
+	depth := 0
+	for ; n < len(lines); n++ {
+		trimmed := strings.TrimSpace(lines[n])
+		if trimmed == "}}}" {
+			if depth == 0 {
+				n++
+				break
+			}
+			depth--
+		} else if isCodeBlockStart(trimmed) {
+			depth++
+		}
+		body = append(body, lines[n])
+	}
+
+	processor := ""
+	if len(body) > 0 {
+		if m := processorRegexp.FindStringSubmatch(strings.TrimSpace(body[0])); m != nil {
+			processor = m[1]
+			body = body[1:]
+		}
+	}
+
+	switch {
+	case processor == "comment":
+		return "", n
+	case processor == "html":
+		return strings.Join(body, "\n"), n
+	case wikiProcessors[processor]:
+		return c.convert(strings.Join(body, "\n")), n
+	case processor == "default" || processor == "text/plain":
+		processor = ""
+	}
+
+	return fence(body, mimeTypePrefix.ReplaceAllString(processor, "")), n
+}
+
+// table converts the rows of the table into a GFM table.
+func (c *markdownConverter) table(lines []string) string {
+	rows := [][]string{}
+	columns := 0
+	for _, line := range lines {
+		row := strings.TrimSuffix(strings.TrimSpace(line), "\\")
+		row = strings.TrimSuffix(strings.TrimPrefix(row, "||"), "||")
+		cells := strings.Split(row, "||")
+		for i, cell := range cells {
+			cell = strings.TrimSpace(cell)
+			if len(cell) >= 2 && strings.HasPrefix(cell, "=") && strings.HasSuffix(cell, "=") {
+				cell = strings.TrimSpace(cell[1 : len(cell)-1])
+			}
+			cells[i] = strings.ReplaceAll(c.inline(cell), "|", `\|`)
+		}
+		if len(cells) > columns {
+			columns = len(cells)
+		}
+		rows = append(rows, cells)
+	}
+
+	b := &strings.Builder{}
+	for i, row := range rows {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+		b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		if i == 0 {
+			b.WriteString(strings.Repeat("| --- ", columns) + "|\n")
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// listLevel represents a level of the nested lists.
+type listLevel struct {
+	indent int
+	width  int
+}
+
+// list converts the items of the lists, nested by the indents.
+func (c *markdownConverter) list(lines []string) string {
+	levels := []listLevel{}
+	width := func(levels []listLevel) int {
+		w := 0
+		for _, level := range levels {
+			w += level.width
+		}
+		return w
+	}
+
+	out := []string{}
+	for _, line := range lines {
+		m := bulletRegexp.FindStringSubmatch(line)
+		if m == nil {
+			m = orderedRegexp.FindStringSubmatch(line)
+		}
+		if m == nil {
+			out = append(out, strings.Repeat(" ", width(levels))+escapeLineStart(c.inline(strings.TrimSpace(line))))
+			continue
+		}
+
+		indent := len(m[1])
+		for len(levels) > 0 && levels[len(levels)-1].indent > indent {
+			levels = levels[:len(levels)-1]
+		}
+		if len(levels) == 0 || levels[len(levels)-1].indent < indent {
+			levels = append(levels, listLevel{indent: indent})
+		}
+		marker := "-"
+		if strings.HasSuffix(m[2], ".") {
+			marker = "1."
+			if _, err := strconv.Atoi(strings.TrimSuffix(m[2], ".")); err == nil {
+				marker = m[2]
+			}
+		}
+		levels[len(levels)-1].width = len(marker) + 1
+		out = append(out, strings.Repeat(" ", width(levels[:len(levels)-1]))+marker+" "+c.inline(m[3]))
+	}
+
+	return strings.Join(out, "\n")
+}
+
+// definitions converts the definition list. Markdown has no definition lists,
+// so the terms are rendered in bold followed by the definitions.
+func (c *markdownConverter) definitions(lines []string) string {
+	items := []string{}
+	var term string
+	var definition []string
+	flush := func() {
+		if term != "" {
+			items = append(items, "**"+c.inline(term)+"**\\\n"+c.inline(strings.Join(definition, "\n")))
+		}
+	}
+	for _, line := range lines {
+		if m := definitionRegexp.FindStringSubmatch(line); m != nil {
+			flush()
+			term, definition = strings.TrimSpace(m[1]), nil
+			if m[2] != "" {
+				definition = append(definition, strings.TrimSpace(m[2]))
+			}
+			continue
+		}
+		definition = append(definition, strings.TrimSpace(line))
+	}
+	flush()
+
+	return strings.Join(items, "\n\n")
+}
+
+// citation converts the lines of the citation, keeping the levels.
+func (c *markdownConverter) citation(lines []string) string {
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		m := citationRegexp.FindStringSubmatch(line)
+		out[i] = strings.TrimRight(m[1]+" "+escapeLineStart(c.inline(m[2])), " ")
+	}
+
+	return strings.Join(out, "\n")
+}
+
+// paragraph converts the lines of the paragraph.
+func (c *markdownConverter) paragraph(lines []string) string {
+	trimmed := make([]string, len(lines))
+	for i, line := range lines {
+		trimmed[i] = strings.TrimSpace(line)
+	}
+	out := []string{}
+	for _, line := range strings.Split(c.inline(strings.Join(trimmed, "\n")), "\n") {
+		// the lines of the hidden macros are removed.
+		if line != "" {
+			out = append(out, escapeLineStart(line))
+		}
+	}
+
+	return strings.Join(out, "\n")
+}
+
+// inline converts the inline markup of the text.
+// The quotes toggle the emphasis as Trac does, and the emphasis left open is closed at the end of the text.
+func (c *markdownConverter) inline(text string) string {
+	b := &strings.Builder{}
+	// open holds the markers of the emphasis toggled on, from the outermost.
+	open := []string{}
+	for i := 0; i < len(text); {
+		if text[i] == '!' {
+			// "!" escapes the markup.
+			if n := quoteRun(text, i+1); n > 0 {
+				b.WriteString(text[i+1 : i+1+n])
+				i += 1 + n
+				continue
+			}
+			if _, n := c.matchInline(text, i+1); n > 0 {
+				b.WriteString(escapeText(text[i+1 : i+1+n]))
+				i += 1 + n
+				continue
+			}
+		}
+		if n := quoteRun(text, i); n > 0 {
+			switch n {
+			case 2:
+				open = toggleEmphasis(b, open, "*")
+			case 3:
+				open = toggleEmphasis(b, open, "**")
+			case 5:
+				// the emphasis closed first is the inner one.
+				markers := []string{"**", "*"}
+				if (len(open) > 0 && open[len(open)-1] == "*") || (len(open) == 0 && quoteRun(text, nextQuotes(text, i+n)) == 3) {
+					markers = []string{"*", "**"}
+				}
+				for _, marker := range markers {
+					open = toggleEmphasis(b, open, marker)
+				}
+			}
+			i += n
+			continue
+		}
+		if out, n := c.matchInline(text, i); n > 0 {
+			b.WriteString(out)
+			i += n
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if r == '_' && isWordBefore(text, i) && isWordAt(text, i+1) {
+			// underscores in a word are not emphasis.
+			b.WriteRune(r)
+		} else {
+			b.WriteString(escapeText(string(r)))
+		}
+		i += size
+	}
+	for j := len(open) - 1; j >= 0; j-- {
+		b.WriteString(open[j])
+	}
+
+	return b.String()
+}
+
+// quoteRun returns the length of the quotes of the emphasis at i, i.e. 5 for bold italic, 3 for bold and 2 for italic,
+// or 0 if there is none. The extra quotes such as the fourth one are left as text.
+func quoteRun(text string, i int) int {
+	n := 0
+	for i+n < len(text) && text[i+n] == '\'' {
+		n++
+	}
+	switch {
+	case n >= 5:
+		return 5
+	case n >= 3:
+		return 3
+	case n == 2:
+		return 2
+	}
+
+	return 0
+}
+
+// nextQuotes returns the position of the next quotes of the emphasis from i, or the length of the text if there is none.
+func nextQuotes(text string, i int) int {
+	for ; i < len(text); i++ {
+		if text[i] == '!' && quoteRun(text, i+1) > 0 {
+			i += quoteRun(text, i+1)
+			continue
+		}
+		if quoteRun(text, i) > 0 {
+			return i
+		}
+	}
+
+	return len(text)
+}
+
+// toggleEmphasis writes the marker to open or close the emphasis, and returns the markers left open.
+// The inner emphasis is closed before the marker, and opened again after it.
+func toggleEmphasis(b *strings.Builder, open []string, marker string) []string {
+	for j := len(open) - 1; j >= 0; j-- {
+		if open[j] != marker {
+			continue
+		}
+		for k := len(open) - 1; k >= j; k-- {
+			b.WriteString(open[k])
+		}
+		for _, inner := range open[j+1:] {
+			b.WriteString(inner)
+		}
+		return append(open[:j], open[j+1:]...)
+	}
+	b.WriteString(marker)
+
+	return append(open, marker)
+}
+
+// matchInline converts the markup at i, and returns the length of the markup, or 0 if there is none.
+func (c *markdownConverter) matchInline(text string, i int) (string, int) {
+	if i >= len(text) {
+		return "", 0
+	}
+	for _, rule := range inlineRules {
+		if rule.boundary && isWordBefore(text, i) {
+			continue
+		}
+		m := rule.re.FindStringSubmatch(text[i:])
+		if m == nil {
+			continue
+		}
+		if out, n := rule.convert(c, m); n > 0 {
+			return out, n
+		}
+	}
+
+	return "", 0
+}
+
+// emphasis returns the function to convert the text between the markers.
+func emphasis(open, close string) func(c *markdownConverter, m []string) (string, int) {
+	return func(c *markdownConverter, m []string) (string, int) {
+		return open + c.inline(m[1]) + close, len(m[0])
+	}
+}
+
+// bareLink converts the TracLink without the brackets, e.g. "wiki:WikiStart", "#1" or "WikiStart".
+func (c *markdownConverter) bareLink(m []string) (string, int) {
+	link, n := parseBareLink(m[0])
+	if n == 0 {
+		return "", 0
+	}
+
+	return c.link(link), n
+}
+
+// bracketLink converts the TracLink in the brackets, e.g. "[wiki:WikiStart label]" or "[1234]".
+func (c *markdownConverter) bracketLink(m []string) (string, int) {
+	link, ok := parseBracketLink(m)
+	if !ok {
+		return "", 0
+	}
+
+	return c.link(link), len(m[0])
+}
+
+// macroOrLink converts the macro, e.g. "[[Image(a.png)]]", or the link, e.g. "[[WikiStart|label]]".
+func (c *markdownConverter) macroOrLink(m []string) (string, int) {
+	if name, args, ok := parseMacro(m[1]); ok {
+		switch {
+		case name == "BR":
+			return "<br>", len(m[0])
+		case name == "Image":
+			return c.image(parseImage(args)), len(m[0])
+		case hiddenMacros[name]:
+			return "", len(m[0])
+		}
+		return escapeText(m[0]), len(m[0])
+	}
+
+	link, ok := parseDoubleBracketLink(m[1])
+	if !ok {
+		return "", 0
+	}
+
+	return c.link(link), len(m[0])
+}
+
+// image renders the image of [[Image]].
+func (c *markdownConverter) image(link Link) string {
+	href := c.options.href(link)
+	if href == "" {
+		return escapeText(link.Label)
+	}
+
+	return "![" + escapeText(link.Label) + "](" + destination(href) + ")"
+}
+
+// link renders the link.
+func (c *markdownConverter) link(link Link) string {
+	href := c.options.href(link)
+	if href == "" {
+		return escapeText(link.Label)
+	}
+	if link.Kind == LinkURL && link.Label == href && !strings.ContainsAny(href, "<> ") {
+		return "<" + href + ">"
+	}
+
+	return "[" + escapeText(link.Label) + "](" + destination(href) + ")"
+}
+
+// isCodeBlockStart reports whether the trimmed line begins a {{{ }}} block.
+func isCodeBlockStart(trimmed string) bool {
+	return strings.HasPrefix(trimmed, "{{{") && !strings.Contains(trimmed[3:], "}}}")
+}
+
+// isListItem reports whether the line is an item of a list.
+func isListItem(line string) bool {
+	return bulletRegexp.MatchString(line) || orderedRegexp.MatchString(line)
+}
+
+// isBlockStart reports whether the line begins a block other than a paragraph.
+func isBlockStart(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" ||
+		isCodeBlockStart(trimmed) ||
+		headingRegexp.MatchString(line) ||
+		ruleRegexp.MatchString(line) ||
+		tableRowRegexp.MatchString(line) ||
+		isListItem(line) ||
+		definitionRegexp.MatchString(line) ||
+		citationRegexp.MatchString(line)
+}
+
+// startsWithSpace reports whether the line is indented.
+func startsWithSpace(line string) bool {
+	return line != "" && (line[0] == ' ' || line[0] == '\t')
+}
+
+// countLines returns the number of the leading lines which satisfy f.
+func countLines(lines []string, f func(line string) bool) int {
+	n := 0
+	for n < len(lines) && f(lines[n]) {
+		n++
+	}
+
+	return n
+}
+
+// prefixLines adds the prefix to each line.
+func prefixLines(text, prefix string) string {
+	return prefix + strings.ReplaceAll(text, "\n", "\n"+prefix)
+}
+
+// fence returns the fenced code block.
+func fence(lines []string, lang string) string {
+	ticks := 3
+	for _, line := range lines {
+		if n := len(line) - len(strings.TrimLeft(strings.TrimSpace(line), "`")); n >= ticks && strings.HasPrefix(strings.TrimSpace(line), "```") {
+			ticks = n + 1
+		}
+	}
+	marker := strings.Repeat("`", ticks)
+	if len(lines) == 0 {
+		return marker + lang + "\n" + marker
+	}
+
+	return marker + lang + "\n" + strings.Join(lines, "\n") + "\n" + marker
+}
+
+// codeSpan returns the inline code.
+func codeSpan(code string) string {
+	if code == "" {
+		return ""
+	}
+	ticks, run := 1, 0
+	for _, r := range code {
+		if r == '`' {
+			run++
+			if run >= ticks {
+				ticks = run + 1
+			}
+		} else {
+			run = 0
+		}
+	}
+	pad := ""
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") || (strings.HasPrefix(code, " ") && strings.HasSuffix(code, " ")) {
+		pad = " "
+	}
+	marker := strings.Repeat("`", ticks)
+
+	return marker + pad + code + pad + marker
+}
+
+// destination returns the link destination, enclosed in the angle brackets if needed.
+func destination(href string) string {
+	if strings.ContainsAny(href, " ()") {
+		return "<" + href + ">"
+	}
+
+	return href
+}
+
+// escapeText escapes the characters which are the markup in Markdown.
+func escapeText(text string) string {
+	b := &strings.Builder{}
+	for _, r := range text {
+		if strings.ContainsRune("\\`*_[]<~", r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// escapeLineStart escapes the line which would begin a block in Markdown, e.g. "# not a heading".
+func escapeLineStart(line string) string {
+	if blockStartRegexp.MatchString(line) {
+		return "\\" + line
+	}
+	if orderedStartRegx.MatchString(line) {
+		return orderedStartRegx.ReplaceAllString(line, "$1\\$2")
+	}
+
+	return line
+}
+
+// isWordBefore reports whether the rune before i is a letter or a digit.
+func isWordBefore(text string, i int) bool {
+	if i <= 0 {
+		return false
+	}
+	r, _ := utf8.DecodeLastRuneInString(text[:i])
+
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// isWordAt reports whether the rune at i is a letter or a digit.
+func isWordAt(text string, i int) bool {
+	if i >= len(text) {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(text[i:])
+
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,80 @@
+package wikiconv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"Empty", "", ""},
+		{"Heading", "= Shiga =\n== Biwako == #lake\n=== Otsu", "# Shiga\n\n## Biwako\n\n### Otsu\n"},
+		{"Emphasis", "'''bold''', ''italic'', '''''both''''', ~~strike~~, __under__, ^sup^ and ,,sub,,",
+			"**bold**, *italic*, ***both***, ~~strike~~, <u>under</u>, <sup>sup</sup> and <sub>sub</sub>\n"},
+		{"NestedEmphasis", "''italic '''bold''' still'' and '''bold ''italic'' still'''", "*italic **bold** still* and **bold *italic* still**\n"},
+		{"BoldItalicToggle", "'''''both''' italic'' '''''both'' bold''' ''open", "***both** italic* ***both* bold** *open*\n"},
+		{"EscapedQuotes", "!''not italic!'' it''''s", "''not italic'' it**'s**\n"},
+		{"InlineCode", "{{{'''raw'''}}} and `a``b`", "`'''raw'''` and `a``b`\n"},
+		{"Escape", "snake_case *star* [x] <b> # 1. !WikiStart !#1", "snake_case \\*star\\* \\[x\\] \\<b> # 1. WikiStart #1\n"},
+		{"LineStart", "text\n# not heading\n2021. not list\n- not list", "text\n\\# not heading\n2021\\. not list\n\n- not list\n"},
+		{"CodeBlock", "{{{\n#!python\ndef f():\n    pass\n}}}", "```python\ndef f():\n    pass\n```\n"},
+		{"CodeBlockSameLine", "{{{#!text/x-sh\necho ```\n}}}", "```sh\necho ```\n```\n"},
+		{"CodeBlockNested", "{{{\n{{{\ninner\n}}}\n}}}", "```\n{{{\ninner\n}}}\n```\n"},
+		{"Comment", "a\n{{{#!comment\nhidden\n}}}\nb", "a\n\nb\n"},
+		{"Div", "{{{#!div class=note\n'''note'''\n}}}", "**note**\n"},
+		{"Rule", "a\n----\nb", "a\n\n---\n\nb\n"},
+		{"List", " * one\n * two\n   * nested\n     continued\n 1. first\n    a. alpha\n 3. third",
+			"- one\n- two\n  - nested\n    continued\n1. first\n   1. alpha\n3. third\n"},
+		{"Definition", " Biwako:: the largest lake\n   in Japan\n Otsu::\n   the capital",
+			"**Biwako**\\\nthe largest lake\nin Japan\n\n**Otsu**\\\nthe capital\n"},
+		{"Quote", "  quoted ''text''\n  more", "> quoted *text*\n> more\n"},
+		{"Citation", "> cite\n>> deeper", "> cite\n>> deeper\n"},
+		{"Table", "||= Lake =||= Area =||\n|| Biwako || 670 ||\n|| a|b ||", "| Lake | Area |\n| --- | --- |\n| Biwako | 670 |\n| a\\|b |  |\n"},
+		{"TracLinks", "wiki:Shiga, ticket:1, #2, [3], r4, changeset:5, {6}, report:7, milestone:1.0, source:trunk/a.go@8, WikiStart.",
+			"[wiki:Shiga](/wiki/Shiga), [ticket:1](/ticket/1), [#2](/ticket/2), [\\[3\\]](/changeset/3), [r4](/changeset/4), [changeset:5](/changeset/5), [{6}](/report/6), [report:7](/report/7), [milestone:1.0](/milestone/1.0), [source:trunk/a.go@8](/browser/trunk/a.go?rev=8), [WikiStart](/wiki/WikiStart).\n"},
+		{"BracketLinks", "[wiki:Dev/Setup setup] [wiki:WikiStart] [ticket:1 ouch] [[Shiga]] [[wiki:Shiga#Otsu|Otsu]] [http://example.com Example]",
+			"[setup](/wiki/Dev/Setup) [WikiStart](/wiki/WikiStart) [ouch](/ticket/1) [Shiga](/wiki/Shiga) [Otsu](/wiki/Shiga#Otsu) [Example](http://example.com)\n"},
+		{"QuotedLinks", "[wiki:\"My Page\" label] [milestone:\"1.0 beta\" m] [wiki:'My Page'] wiki:\"Other Page\"",
+			"[label](/wiki/My%20Page) [m](/milestone/1.0%20beta) [My Page](/wiki/My%20Page) [wiki:\"Other Page\"](/wiki/Other%20Page)\n"},
+		{"URL", "see http://example.com/a_b. and mailto:ii@example.com", "see <http://example.com/a_b>. and <mailto:ii@example.com>\n"},
+		{"Attachment", "attachment:a.txt attachment:b.txt:ticket:1 [attachment:c.txt:wiki:Shiga c]",
+			"[attachment:a.txt](/attachment/wiki/WikiStart/a.txt) [attachment:b.txt:ticket:1](/attachment/ticket/1/b.txt) [c](/attachment/wiki/Shiga/c.txt)\n"},
+		{"Image", "[[Image(a.png)]] [[Image(wiki:Shiga:b.png, alt=Biwako)]] [[Image(http://example.com/c.png)]]",
+			"![a.png](/raw-attachment/wiki/WikiStart/a.png) ![Biwako](/raw-attachment/wiki/Shiga/b.png) ![http://example.com/c.png](http://example.com/c.png)\n"},
+		{"Macros", "[[PageOutline]]\na[[BR]]b [[TicketQuery(status=new)]]", "a<br>b \\[\\[TicketQuery(status=new)\\]\\]\n"},
+		{"NotLinks", "HTTP, iPhone, note:, foo:bar, [not a link]", "HTTP, iPhone, note:, foo:bar, \\[not a link\\]\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ToMarkdown(tt.text, WithPage("WikiStart"))
+			if got != tt.expected {
+				t.Fatalf("unexpected result. expected=%q, got=%q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestToMarkdownLinkRewriter(t *testing.T) {
+	rewriter := func(link Link, href string) string {
+		switch link.Kind {
+		case LinkWiki:
+			target, fragment := splitFragment(link.Target)
+			return strings.ToLower(target) + ".md" + fragment
+		case LinkChangeset:
+			return ""
+		}
+		return href
+	}
+
+	text := "WikiStart [wiki:Dev/Setup#Go setup] r1 #2 [[Image(a.png)]]"
+	expected := "[WikiStart](wikistart.md) [setup](dev/setup.md#Go) r1 [#2](https://example.com/trac/ticket/2) ![a.png](https://example.com/trac/raw-attachment/wiki/Dev/Start/a.png)\n"
+	got := ToMarkdown(text, WithBaseURL("https://example.com/trac/"), WithPage("Dev/Start"), WithLinkRewriter(rewriter))
+	if got != expected {
+		t.Fatalf("unexpected result. expected=%q, got=%q", expected, got)
+	}
+}
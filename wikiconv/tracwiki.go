@@ -0,0 +1,670 @@
+package wikiconv
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	atxHeadingRegexp    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:\s+(.*?))?(?:\s+#+)?\s*$`)
+	setextRegexp        = regexp.MustCompile(`^ {0,3}(=+|-+)\s*$`)
+	breakRegexp         = regexp.MustCompile(`^ {0,3}([-*_])(?:\s*([-*_]))(?:\s*([-*_]))+\s*$`)
+	fenceRegexp         = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})\\s*([^`\\s]*)")
+	mdListItemRegexp    = regexp.MustCompile(`^(\s*)([-*+]|\d{1,9}[.)])(?:\s+(.*))?$`)
+	mdQuoteRegexp       = regexp.MustCompile(`^ {0,3}>\s?`)
+	htmlBlockRegexp     = regexp.MustCompile(`^ {0,3}<(?:!--|/?[a-zA-Z][a-zA-Z0-9-]*(?:\s|/?>|$))`)
+	delimiterRowRegexp  = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(?:\|\s*:?-+:?\s*)*\|?\s*$`)
+	referenceRegexp     = regexp.MustCompile(`^ {0,3}\[([^\]]+)\]:\s*(<[^>]*>|\S+)(?:\s+(?:"[^"]*"|'[^']*'|\([^)]*\)))?\s*$`)
+	hardBreakRegexp     = regexp.MustCompile(`(?: {2,}|\\)$`)
+	markdownExtRegexp   = regexp.MustCompile(`\.(?:md|markdown|wiki)$`)
+	tracBracketRegexp   = regexp.MustCompile(`^\[([^\[\]\s]+)(?:\s+[^\]]*)?\]`)
+	tracCamelCaseRegexp = regexp.MustCompile(`^(?:[A-Z][a-z0-9]+){2,}(?:/(?:[A-Z][a-z0-9]+){2,})*\b`)
+)
+
+// attachmentsDirSuffix is the suffix of the directories of the attachments, as WikiSync of tracrpc mirrors.
+const attachmentsDirSuffix = ".attachments"
+
+// tracMarkup is the markup of Trac which is escaped by "!" in the plain text.
+var tracMarkup = []string{"'''", "''", "{{{", "}}}", "~~", "__", ",,", "[["}
+
+// markdownRule represents a rule of the inline markup of Markdown.
+type markdownRule struct {
+	re *regexp.Regexp
+	// boundary reports whether the match must not be in a word.
+	boundary bool
+	// convert converts the match, and returns the length of the text consumed, or 0 if it is not the markup.
+	convert func(c *tracConverter, m []string) (string, int)
+}
+
+// markdownRules are tried in order at each position of the text.
+// They are initialized in init, since the rules convert the nested markup by themselves.
+var markdownRules []markdownRule
+
+func init() {
+	markdownRules = []markdownRule{
+		{re: regexp.MustCompile("^\\\\([!-/:-@\\[-`{-~])"), convert: func(c *tracConverter, m []string) (string, int) {
+			return c.plain(m[1]), len(m[0])
+		}},
+		{re: regexp.MustCompile(`^<((?:[a-zA-Z][a-zA-Z0-9+.-]*:|mailto:)[^\s<>]+)>`), convert: func(c *tracConverter, m []string) (string, int) {
+			return m[1], len(m[0])
+		}},
+		{re: regexp.MustCompile(`^<br\s*/?>`), convert: func(c *tracConverter, m []string) (string, int) {
+			return "[[BR]]", len(m[0])
+		}},
+		{re: regexp.MustCompile(`^!\[([^\]]*)\]\(\s*(<[^>]*>|[^\s)]+)(?:\s+(?:"[^"]*"|'[^']*'))?\s*\)`), convert: func(c *tracConverter, m []string) (string, int) {
+			return c.image(m[1], m[2]), len(m[0])
+		}},
+		{re: regexp.MustCompile(`^\[((?:[^\[\]]|\[[^\]]*\])*)\]\(\s*(<[^>]*>|[^\s)]*)(?:\s+(?:"[^"]*"|'[^']*'))?\s*\)`), convert: func(c *tracConverter, m []string) (string, int) {
+			return c.link(m[1], m[2]), len(m[0])
+		}},
+		{re: regexp.MustCompile(`^\[([^\]]+)\]\[([^\]]*)\]`), convert: (*tracConverter).referenceLink},
+		{re: regexp.MustCompile(`^\[([^\]]+)\]`), convert: (*tracConverter).referenceLink},
+		{re: regexp.MustCompile(`(?s)^\*\*\*(\S(?:.*?\S)?)\*\*\*`), convert: tracEmphasis("'''''")},
+		{re: regexp.MustCompile(`(?s)^\*\*(\S(?:.*?\S)?)\*\*`), convert: tracEmphasis("'''")},
+		{re: regexp.MustCompile(`(?s)^__(\S(?:.*?\S)?)__`), boundary: true, convert: tracEmphasis("'''")},
+		{re: regexp.MustCompile(`(?s)^\*(\S(?:.*?\S)?)\*`), convert: tracEmphasis("''")},
+		{re: regexp.MustCompile(`(?s)^_(\S(?:.*?\S)?)_`), boundary: true, convert: tracEmphasis("''")},
+		{re: regexp.MustCompile(`(?s)^~~(\S(?:.*?\S)?)~~`), convert: tracEmphasis("~~")},
+	}
+}
+
+// tracConverter represents a conversion from Markdown to Trac WikiFormatting.
+type tracConverter struct {
+	options    options
+	references map[string]string
+}
+
+// FromMarkdown converts CommonMark, including GFM tables and fenced code blocks, into Trac WikiFormatting.
+// The CamelCase words are escaped by "!" not to be links, and the relative links are converted into
+// wiki: links, resolved against the page set by WithPage.
+func FromMarkdown(text string, opts ...Option) string {
+	c := &tracConverter{
+		options:    newOptions(opts),
+		references: make(map[string]string),
+	}
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	body := []string{}
+	inFence := ""
+	for _, line := range lines {
+		// the link reference definitions are resolved into the links.
+		if m := fenceRegexp.FindStringSubmatch(line); m != nil {
+			if inFence == "" {
+				inFence = m[2]
+			} else if strings.HasPrefix(m[2], inFence[:1]) && len(m[2]) >= len(inFence) {
+				inFence = ""
+			}
+		}
+		if m := referenceRegexp.FindStringSubmatch(line); m != nil && inFence == "" {
+			c.references[strings.ToLower(m[1])] = m[2]
+			continue
+		}
+		body = append(body, line)
+	}
+
+	wiki := c.convert(body)
+	if wiki == "" {
+		return ""
+	}
+
+	return wiki + "\n"
+}
+
+// convert converts the blocks of the lines.
+func (c *tracConverter) convert(lines []string) string {
+	blocks := []string{}
+	add := func(block string) {
+		if strings.TrimSpace(block) != "" {
+			blocks = append(blocks, block)
+		}
+	}
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+		case fenceRegexp.MatchString(line):
+			block, n := c.fencedCode(lines[i:])
+			add(block)
+			i += n
+		case isIndentedCode(line):
+			n := countLines(lines[i:], func(line string) bool {
+				return isIndentedCode(line) || strings.TrimSpace(line) == ""
+			})
+			for n > 0 && strings.TrimSpace(lines[i+n-1]) == "" {
+				n--
+			}
+			code := make([]string, n)
+			for j, line := range lines[i : i+n] {
+				code[j] = strings.TrimPrefix(strings.Replace(line, "\t", "    ", 1), "    ")
+			}
+			add(tracCodeBlock(code, ""))
+			i += n
+		case atxHeadingRegexp.MatchString(line):
+			m := atxHeadingRegexp.FindStringSubmatch(line)
+			add(heading(len(m[1]), c.inline(m[2])))
+			i++
+		case breakRegexp.MatchString(line):
+			add("----")
+			i++
+		case htmlBlockRegexp.MatchString(line):
+			n := countLines(lines[i:], func(line string) bool {
+				return strings.TrimSpace(line) != ""
+			})
+			html := strings.Join(lines[i:i+n], "\n")
+			if strings.HasPrefix(strings.TrimSpace(html), "<!--") {
+				add(tracCodeBlock(lines[i:i+n], "comment"))
+			} else {
+				add(tracCodeBlock(lines[i:i+n], "html"))
+			}
+			i += n
+		case mdQuoteRegexp.MatchString(line):
+			n := countLines(lines[i:], func(line string) bool {
+				return mdQuoteRegexp.MatchString(line)
+			})
+			inner := make([]string, n)
+			for j, line := range lines[i : i+n] {
+				inner[j] = mdQuoteRegexp.ReplaceAllString(line, "")
+			}
+			add(prefixQuote(c.convert(inner)))
+			i += n
+		case mdListItemRegexp.MatchString(line):
+			n := c.listLength(lines[i:])
+			add(c.list(lines[i : i+n]))
+			i += n
+		case i+1 < len(lines) && strings.Contains(line, "|") && delimiterRowRegexp.MatchString(lines[i+1]) && strings.Contains(lines[i+1], "|"):
+			n := 2 + countLines(lines[i+2:], func(line string) bool {
+				return strings.TrimSpace(line) != "" && strings.Contains(line, "|")
+			})
+			add(c.table(lines[i : i+n]))
+			i += n
+		default:
+			n := 1
+			level := 0
+			for ; i+n < len(lines); n++ {
+				next := lines[i+n]
+				if m := setextRegexp.FindStringSubmatch(next); m != nil {
+					level = 1
+					if m[1][0] == '-' {
+						level = 2
+					}
+					break
+				}
+				if strings.TrimSpace(next) == "" || fenceRegexp.MatchString(next) || atxHeadingRegexp.MatchString(next) ||
+					breakRegexp.MatchString(next) || mdQuoteRegexp.MatchString(next) || htmlBlockRegexp.MatchString(next) ||
+					(mdListItemRegexp.MatchString(next) && strings.TrimSpace(mdListItemRegexp.FindStringSubmatch(next)[3]) != "") {
+					break
+				}
+			}
+			if level > 0 {
+				add(heading(level, c.inline(strings.TrimSpace(strings.Join(trimLines(lines[i:i+n]), " ")))))
+				i += n + 1
+				continue
+			}
+			add(c.paragraph(lines[i : i+n]))
+			i += n
+		}
+	}
+
+	return strings.Join(blocks, "\n\n")
+}
+
+// fencedCode converts the fenced code block at the beginning of the lines, and returns the number of the lines.
+func (c *tracConverter) fencedCode(lines []string) (string, int) {
+	m := fenceRegexp.FindStringSubmatch(lines[0])
+	indent, marker, lang := len(m[1]), m[2], m[3]
+	code := []string{}
+	n := 1
+	for ; n < len(lines); n++ {
+		if mm := fenceRegexp.FindStringSubmatch(lines[n]); mm != nil && mm[3] == "" &&
+			mm[2][0] == marker[0] && len(mm[2]) >= len(marker) {
+			n++
+			break
+		}
+		line := lines[n]
+		for j := 0; j < indent && strings.HasPrefix(line, " "); j++ {
+			line = line[1:]
+		}
+		code = append(code, line)
+	}
+	if lang == "text" || lang == "plain" {
+		lang = ""
+	}
+
+	return tracCodeBlock(code, lang), n
+}
+
+// listLength returns the number of the lines of the list at the beginning of the lines.
+func (c *tracConverter) listLength(lines []string) int {
+	n := 1
+	for n < len(lines) {
+		line := lines[n]
+		if strings.TrimSpace(line) == "" {
+			// the list continues after the blank lines if the next item or an indented line follows.
+			blank := countLines(lines[n:], func(line string) bool {
+				return strings.TrimSpace(line) == ""
+			})
+			if n+blank < len(lines) && (mdListItemRegexp.MatchString(lines[n+blank]) || startsWithSpace(lines[n+blank])) {
+				n += blank
+				continue
+			}
+			break
+		}
+		if !mdListItemRegexp.MatchString(line) && !startsWithSpace(line) && (fenceRegexp.MatchString(line) ||
+			atxHeadingRegexp.MatchString(line) || breakRegexp.MatchString(line) || mdQuoteRegexp.MatchString(line)) {
+			break
+		}
+		n++
+	}
+
+	return n
+}
+
+// list converts the items of the lists, nested by the indents.
+func (c *tracConverter) list(lines []string) string {
+	levels := []int{}
+	out := []string{}
+	continuation := ""
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		m := mdListItemRegexp.FindStringSubmatch(line)
+		if m == nil {
+			out = append(out, continuation+c.inline(strings.TrimSpace(line)))
+			continue
+		}
+
+		indent := len(strings.ReplaceAll(m[1], "\t", "    "))
+		for len(levels) > 0 && levels[len(levels)-1] > indent {
+			levels = levels[:len(levels)-1]
+		}
+		if len(levels) == 0 || levels[len(levels)-1] < indent {
+			levels = append(levels, indent)
+		}
+		prefix := strings.Repeat("  ", len(levels)-1) + " "
+		marker := "*"
+		if m[2] != "-" && m[2] != "*" && m[2] != "+" {
+			marker = strings.TrimRight(m[2], ".)") + "."
+		}
+		continuation = strings.Repeat(" ", len(prefix)+len(marker)+1)
+		out = append(out, prefix+marker+" "+c.inline(strings.TrimSpace(m[3])))
+	}
+
+	return strings.Join(out, "\n")
+}
+
+// table converts the GFM table into a Trac table.
+func (c *tracConverter) table(lines []string) string {
+	out := []string{}
+	for i, line := range lines {
+		if i == 1 {
+			// the delimiter row.
+			continue
+		}
+		cells := splitTableRow(line)
+		for j, cell := range cells {
+			cell = c.inline(strings.TrimSpace(cell))
+			if i == 0 {
+				cells[j] = "= " + cell + " ="
+			} else {
+				cells[j] = " " + cell + " "
+			}
+		}
+		out = append(out, "||"+strings.Join(cells, "||")+"||")
+	}
+
+	return strings.Join(out, "\n")
+}
+
+// splitTableRow splits the row of the GFM table into the cells.
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	cells := []string{}
+	b := &strings.Builder{}
+	inCode := false
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			b.WriteByte('|')
+			i++
+			continue
+		case line[i] == '`':
+			inCode = !inCode
+		case line[i] == '|' && !inCode:
+			cells = append(cells, b.String())
+			b.Reset()
+			continue
+		}
+		b.WriteByte(line[i])
+	}
+
+	return append(cells, b.String())
+}
+
+// paragraph converts the lines of the paragraph.
+func (c *tracConverter) paragraph(lines []string) string {
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		hardBreak := i < len(lines)-1 && hardBreakRegexp.MatchString(line)
+		line = strings.TrimSpace(line)
+		if hardBreak {
+			line = strings.TrimSpace(strings.TrimSuffix(line, `\`)) + "[[BR]]"
+		}
+		out[i] = line
+	}
+
+	return c.inline(strings.Join(out, "\n"))
+}
+
+// inline converts the inline markup of the text.
+func (c *tracConverter) inline(text string) string {
+	b := &strings.Builder{}
+	for i := 0; i < len(text); {
+		if text[i] == '`' {
+			out, n := markdownCodeSpan(text, i)
+			if n == 0 {
+				// the backticks without the closing ones are the plain text.
+				n = len(text[i:]) - len(strings.TrimLeft(text[i:], "`"))
+				out = text[i : i+n]
+			}
+			b.WriteString(out)
+			i += n
+			continue
+		}
+		if out, n := c.matchInline(text, i); n > 0 {
+			b.WriteString(out)
+			i += n
+			continue
+		}
+		if strings.HasPrefix(text[i:], "[[BR]]") {
+			b.WriteString("[[BR]]")
+			i += len("[[BR]]")
+			continue
+		}
+
+		n := len(text) - i
+		for j := i + 1; j < len(text); j++ {
+			if strings.ContainsRune("\\`<![*_~", rune(text[j])) {
+				n = j - i
+				break
+			}
+		}
+		// the plain text is written as a whole to find the CamelCase words.
+		_, size := utf8.DecodeRuneInString(text[i:])
+		if n < size {
+			n = size
+		}
+		b.WriteString(c.plainAt(text, i, i+n))
+		i += n
+	}
+
+	return b.String()
+}
+
+// matchInline converts the markup at i, and returns the length of the markup, or 0 if there is none.
+func (c *tracConverter) matchInline(text string, i int) (string, int) {
+	for _, rule := range markdownRules {
+		if rule.boundary && isWordBefore(text, i) {
+			continue
+		}
+		m := rule.re.FindStringSubmatch(text[i:])
+		if m == nil {
+			continue
+		}
+		if rule.boundary && isWordAt(text, i+len(m[0])) {
+			continue
+		}
+		if out, n := rule.convert(c, m); n > 0 {
+			return out, n
+		}
+	}
+
+	return "", 0
+}
+
+// plain escapes the plain text.
+func (c *tracConverter) plain(text string) string {
+	return c.plainAt(text, 0, len(text))
+}
+
+// plainAt escapes text[start:end] with "!" where Trac would find the markup or the links.
+func (c *tracConverter) plainAt(text string, start, end int) string {
+	b := &strings.Builder{}
+	for i := start; i < end; {
+		rest := text[i:end]
+		if !isWordBefore(text, i) {
+			if m := tracCamelCaseRegexp.FindString(rest); m != "" && !isWordAt(text, i+len(m)) {
+				b.WriteString("!" + m)
+				i += len(m)
+				continue
+			}
+		}
+		if m := tracBracketRegexp.FindStringSubmatch(rest); m != nil {
+			if _, ok := parseLink(m[1]); ok {
+				b.WriteString("!")
+			}
+		}
+		for _, markup := range tracMarkup {
+			if strings.HasPrefix(rest, markup) {
+				b.WriteString("!" + markup)
+				i += len(markup)
+				rest = ""
+				break
+			}
+		}
+		if rest == "" {
+			continue
+		}
+		if rest[0] == '^' && strings.Contains(rest[1:], "^") {
+			b.WriteString("!")
+		}
+
+		_, size := utf8.DecodeRuneInString(rest)
+		b.WriteString(rest[:size])
+		i += size
+	}
+
+	return b.String()
+}
+
+// markdownCodeSpan converts the code span at i, and returns the length of the code span, or 0 if there is none.
+func markdownCodeSpan(text string, i int) (string, int) {
+	ticks := len(text[i:]) - len(strings.TrimLeft(text[i:], "`"))
+	marker := text[i : i+ticks]
+	for j := i + ticks; j < len(text); {
+		k := strings.Index(text[j:], marker)
+		if k < 0 {
+			break
+		}
+		end := j + k
+		run := len(text[end:]) - len(strings.TrimLeft(text[end:], "`"))
+		if run != ticks {
+			j = end + run
+			continue
+		}
+
+		code := strings.ReplaceAll(text[i+ticks:end], "\n", " ")
+		if len(code) >= 2 && strings.HasPrefix(code, " ") && strings.HasSuffix(code, " ") && strings.TrimSpace(code) != "" {
+			code = code[1 : len(code)-1]
+		}
+		switch {
+		case !strings.Contains(code, "`"):
+			return "`" + code + "`", end + ticks - i
+		case !strings.Contains(code, "}}}"):
+			return "{{{" + code + "}}}", end + ticks - i
+		}
+		return "{{{" + strings.ReplaceAll(code, "}}}", "} } }") + "}}}", end + ticks - i
+	}
+
+	return "", 0
+}
+
+// referenceLink converts the link to the link reference definition, e.g. "[label][ref]" or "[ref]".
+func (c *tracConverter) referenceLink(m []string) (string, int) {
+	ref := m[1]
+	if len(m) > 2 && m[2] != "" {
+		ref = m[2]
+	}
+	dest, ok := c.references[strings.ToLower(ref)]
+	if !ok {
+		return "", 0
+	}
+
+	return c.link(m[1], dest), len(m[0])
+}
+
+// link converts the link into a TracLink.
+func (c *tracConverter) link(label, dest string) string {
+	dest = strings.TrimSuffix(strings.TrimPrefix(dest, "<"), ">")
+	label = plainLabel(label)
+
+	var target string
+	switch {
+	case urlRegexp.MatchString(dest):
+		if label == "" || label == dest {
+			return dest
+		}
+		target = strings.ReplaceAll(dest, " ", "%20")
+	case strings.HasPrefix(dest, "/"):
+		target = strings.ReplaceAll(dest, " ", "%20")
+	case strings.HasPrefix(dest, "#"):
+		if c.options.page == "" {
+			target = dest
+		} else {
+			target = "wiki:" + quoteTarget(c.options.page) + dest
+		}
+	default:
+		page, fragment := splitFragment(dest)
+		page = c.resolvePage(markdownExtRegexp.ReplaceAllString(page, ""))
+		if page == "" {
+			page = c.options.page
+		}
+		if page == "" {
+			return c.plain(label)
+		}
+		target = "wiki:" + quoteTarget(page) + fragment
+		if label == page {
+			// Trac shows the page name by default.
+			label = ""
+		}
+	}
+
+	if label == "" {
+		return "[" + target + "]"
+	}
+
+	return "[" + target + " " + label + "]"
+}
+
+// image converts the image into [[Image]].
+func (c *tracConverter) image(alt, src string) string {
+	src = strings.TrimSuffix(strings.TrimPrefix(src, "<"), ">")
+	target := src
+	if !urlRegexp.MatchString(src) && !strings.HasPrefix(src, "/") {
+		dir, file := path.Split(src)
+		if dir != "" {
+			page := c.resolvePage(strings.TrimSuffix(strings.TrimSuffix(dir, "/"), attachmentsDirSuffix))
+			target = "wiki:" + page + ":" + file
+		}
+	}
+
+	alt = plainLabel(alt)
+	if alt == "" || strings.ContainsAny(alt, ",)") {
+		return "[[Image(" + target + ")]]"
+	}
+
+	return "[[Image(" + target + ", alt=" + alt + ")]]"
+}
+
+// resolvePage resolves the relative path against the directory of the page being converted.
+func (c *tracConverter) resolvePage(rel string) string {
+	if rel == "" {
+		return ""
+	}
+	resolved := path.Join(path.Dir(c.options.page), rel)
+	if resolved == "." {
+		return ""
+	}
+
+	return strings.TrimPrefix(resolved, "/")
+}
+
+// tracEmphasis returns the function to convert the text between the markers.
+func tracEmphasis(marker string) func(c *tracConverter, m []string) (string, int) {
+	return func(c *tracConverter, m []string) (string, int) {
+		return marker + c.inline(m[1]) + marker, len(m[0])
+	}
+}
+
+// tracCodeBlock returns the {{{ }}} block with the processor.
+func tracCodeBlock(lines []string, processor string) string {
+	b := &strings.Builder{}
+	b.WriteString("{{{\n")
+	if processor != "" {
+		b.WriteString("#!" + processor + "\n")
+	}
+	for _, line := range lines {
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("}}}")
+
+	return b.String()
+}
+
+// heading returns the heading of the level.
+func heading(level int, text string) string {
+	marker := strings.Repeat("=", level)
+	return marker + " " + text + " " + marker
+}
+
+// prefixQuote prefixes each line with the citation marker.
+func prefixQuote(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ">") {
+			lines[i] = ">" + line
+		} else if line != "" {
+			lines[i] = "> " + line
+		} else {
+			lines[i] = ">"
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// isIndentedCode reports whether the line is indented code.
+func isIndentedCode(line string) bool {
+	return (strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t")) && strings.TrimSpace(line) != ""
+}
+
+// trimLines trims the spaces of the lines.
+func trimLines(lines []string) []string {
+	trimmed := make([]string, len(lines))
+	for i, line := range lines {
+		trimmed[i] = strings.TrimSpace(line)
+	}
+
+	return trimmed
+}
+
+// plainLabel removes the emphasis and the code markers from the label, which Trac shows as is.
+func plainLabel(label string) string {
+	return strings.TrimSpace(strings.NewReplacer("***", "", "**", "", "__", "", "`", "", "*", "").Replace(label))
+}
+
+// quoteTarget quotes the page name which contains spaces.
+func quoteTarget(page string) string {
+	if strings.ContainsAny(page, " ]") {
+		return `"` + page + `"`
+	}
+
+	return page
+}
@@ -0,0 +1,45 @@
+package wikiconv
+
+import (
+	"testing"
+)
+
+func TestFromMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"Empty", "", ""},
+		{"Heading", "# Shiga\n## Biwako ##\nOtsu\n----\nKusatsu\n===", "= Shiga =\n\n== Biwako ==\n\n== Otsu ==\n\n= Kusatsu =\n"},
+		{"Emphasis", "**bold**, __bold__, *italic*, _italic_, ***both*** and ~~strike~~",
+			"'''bold''', '''bold''', ''italic'', ''italic'', '''''both''''' and ~~strike~~\n"},
+		{"CodeSpan", "`a` `` b`c `` `{{{x}}}` `unclosed", "`a` {{{b`c}}} `{{{x}}}` `unclosed\n"},
+		{"Escape", "JavaScript, CamelCase/SubPage, snake_case, [1], [wiki:X], [x], ''q'', {{{c}}}, x^2^, \\*star\\*",
+			"!JavaScript, !CamelCase/SubPage, snake_case, ![1], ![wiki:X], [x], !''q!'', !{{{c!}}}, x!^2^, *star*\n"},
+		{"HardBreak", "a  \nb\\\nc", "a[[BR]]\nb[[BR]]\nc\n"},
+		{"Rule", "a\n\n***\n\nb", "a\n\n----\n\nb\n"},
+		{"FencedCode", "```go\nfunc main() {}\n```\n~~~~\n```\n~~~~", "{{{\n#!go\nfunc main() {}\n}}}\n\n{{{\n```\n}}}\n"},
+		{"IndentedCode", "    a\n\n    b\nc", "{{{\na\n\nb\n}}}\n\nc\n"},
+		{"List", "- one\n- two\n  - nested\n    continued\n\n1. first\n2) second", " * one\n * two\n   * nested\n     continued\n 1. first\n 2. second\n"},
+		{"Quote", "> quote **x**\n> > nested", "> quote '''x'''\n>\n>> nested\n"},
+		{"Table", "| Lake | Area |\n| :--- | ---: |\n| Biwako | `6|70` |\n| a \\| b |", "||= Lake =||= Area =||\n|| Biwako || `6|70` ||\n|| a | b ||\n"},
+		{"HTML", "<div>\nraw\n</div>\n\n<!-- note -->", "{{{\n#!html\n<div>\nraw\n</div>\n}}}\n\n{{{\n#!comment\n<!-- note -->\n}}}\n"},
+		{"Links", "[ext](http://example.com \"title\") <http://example.com/a> [root](/newticket) [Setup](Setup.md#Go) [up](../Other) [anchor](#sec) [Dev/Setup](Setup)",
+			"[http://example.com ext] http://example.com/a [/newticket root] [wiki:Dev/Setup#Go Setup] [wiki:Other up] [wiki:Dev/Start#sec anchor] [wiki:Dev/Setup]\n"},
+		{"ReferenceLinks", "[full][r] [Collapsed][] [r] [unknown]\n\n[r]: Setup.md\n[collapsed]: <http://example.com>",
+			"[wiki:Dev/Setup full] [http://example.com Collapsed] [wiki:Dev/Setup r] [unknown]\n"},
+		{"Images", "![logo](logo.png) ![a, b](Start.attachments/b.png) ![](http://example.com/c.png)",
+			"[[Image(logo.png, alt=logo)]] [[Image(wiki:Dev/Start:b.png)]] [[Image(http://example.com/c.png)]]\n"},
+		{"Break", "a<br>b", "a[[BR]]b\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FromMarkdown(tt.text, WithPage("Dev/Start"))
+			if got != tt.expected {
+				t.Fatalf("unexpected result. expected=%q, got=%q", tt.expected, got)
+			}
+		})
+	}
+}
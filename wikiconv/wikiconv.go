@@ -0,0 +1,236 @@
+// Package wikiconv converts between Trac WikiFormatting and Markdown.
+package wikiconv
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// LinkKind represents the kind of a TracLink.
+type LinkKind string
+
+const (
+	// LinkWiki is a link to a wiki page, e.g. wiki:WikiStart or WikiStart.
+	LinkWiki LinkKind = "wiki"
+	// LinkTicket is a link to a ticket, e.g. ticket:1 or #1.
+	LinkTicket LinkKind = "ticket"
+	// LinkChangeset is a link to a changeset, e.g. changeset:1234, [1234] or r1234.
+	LinkChangeset LinkKind = "changeset"
+	// LinkReport is a link to a report, e.g. report:1 or {1}.
+	LinkReport LinkKind = "report"
+	// LinkMilestone is a link to a milestone, e.g. milestone:1.0.
+	LinkMilestone LinkKind = "milestone"
+	// LinkAttachment is a link to an attachment, e.g. attachment:file.txt:wiki:WikiStart.
+	LinkAttachment LinkKind = "attachment"
+	// LinkSource is a link to the repository browser, e.g. source:trunk/README.
+	LinkSource LinkKind = "source"
+	// LinkURL is a link to an external URL.
+	LinkURL LinkKind = "url"
+)
+
+// linkKinds maps the prefixes of TracLinks to the kinds.
+var linkKinds = map[string]LinkKind{
+	"wiki":       LinkWiki,
+	"ticket":     LinkTicket,
+	"bug":        LinkTicket,
+	"changeset":  LinkChangeset,
+	"report":     LinkReport,
+	"milestone":  LinkMilestone,
+	"attachment": LinkAttachment,
+	"source":     LinkSource,
+	"browser":    LinkSource,
+	"repos":      LinkSource,
+}
+
+// Link represents a TracLink.
+type Link struct {
+	Kind LinkKind
+	// Target is the page name, the ticket number, the file name of the attachment, the path or the URL,
+	// followed by the fragment or the query if any, e.g. "WikiStart#Install".
+	Target string
+	// Parent is the resource of the attachment, e.g. "wiki:WikiStart" or "ticket:1".
+	// It is empty if the attachment belongs to the page being converted.
+	Parent string
+	// Label is the text of the link.
+	Label string
+	// Image reports whether the target is embedded by [[Image]].
+	Image bool
+	// Position is the byte offset of the link in the text. It is set only by Links.
+	Position int
+}
+
+// LinkRewriter rewrites href, which is the default URL of the link.
+// The link is rendered as plain text if it returns an empty string.
+type LinkRewriter func(link Link, href string) string
+
+// Option represents an option of the conversions.
+type Option func(*options)
+
+// options represents the options of the conversions.
+type options struct {
+	baseURL  string
+	page     string
+	rewriter LinkRewriter
+}
+
+// WithBaseURL sets the URL of the Trac environment, e.g. "https://example.com/trac".
+// The links are rendered as absolute paths such as "/wiki/WikiStart" by default.
+func WithBaseURL(baseURL string) Option {
+	return func(o *options) {
+		o.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithPage sets the name of the page being converted, which the attachments without the parent belong to.
+func WithPage(name string) Option {
+	return func(o *options) {
+		o.page = name
+	}
+}
+
+// WithLinkRewriter sets the function to rewrite the URLs of the links.
+func WithLinkRewriter(rewriter LinkRewriter) Option {
+	return func(o *options) {
+		o.rewriter = rewriter
+	}
+}
+
+// newOptions creates new options instance.
+func newOptions(opts []Option) options {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+var (
+	camelCaseRegexp = regexp.MustCompile(`^(?:[A-Z][a-z0-9]+){2,}(?:/(?:[A-Z][a-z0-9]+){2,})*`)
+	urlRegexp       = regexp.MustCompile(`^(?:[a-zA-Z][a-zA-Z0-9+.-]*://|mailto:)`)
+	changesetRegexp = regexp.MustCompile(`^r?(\d+)$`)
+	ticketRegexp    = regexp.MustCompile(`^#(\d+)$`)
+	reportRegexp    = regexp.MustCompile(`^\{(\d+)\}$`)
+	prefixRegexp    = regexp.MustCompile(`^([a-z]+):(.+)$`)
+)
+
+// isCamelCase reports whether s is a CamelCase page name.
+func isCamelCase(s string) bool {
+	return camelCaseRegexp.FindString(s) == s
+}
+
+// parseLink parses the TracLink such as "wiki:WikiStart", "#1" or "[1234]" without the brackets.
+func parseLink(spec string) (Link, bool) {
+	switch {
+	case urlRegexp.MatchString(spec):
+		return Link{Kind: LinkURL, Target: spec}, true
+	case changesetRegexp.MatchString(spec):
+		return Link{Kind: LinkChangeset, Target: changesetRegexp.FindStringSubmatch(spec)[1]}, true
+	case ticketRegexp.MatchString(spec):
+		return Link{Kind: LinkTicket, Target: ticketRegexp.FindStringSubmatch(spec)[1]}, true
+	case reportRegexp.MatchString(spec):
+		return Link{Kind: LinkReport, Target: reportRegexp.FindStringSubmatch(spec)[1]}, true
+	case isCamelCase(spec):
+		return Link{Kind: LinkWiki, Target: spec}, true
+	}
+
+	m := prefixRegexp.FindStringSubmatch(spec)
+	if m == nil {
+		return Link{}, false
+	}
+	kind, ok := linkKinds[m[1]]
+	if !ok {
+		return Link{}, false
+	}
+	target := m[2]
+	if len(target) >= 2 && (target[0] == '"' || target[0] == '\'') && target[len(target)-1] == target[0] {
+		target = target[1 : len(target)-1]
+	}
+	link := Link{Kind: kind, Target: target}
+	if kind == LinkAttachment {
+		link.Target, link.Parent = splitAttachment(target)
+	}
+
+	return link, true
+}
+
+// splitAttachment splits "file.txt:wiki:WikiStart" into the file name and the parent.
+func splitAttachment(target string) (string, string) {
+	for _, realm := range []string{":wiki:", ":ticket:"} {
+		if i := strings.Index(target, realm); i > 0 {
+			return target[:i], target[i+1:]
+		}
+	}
+
+	return target, ""
+}
+
+// href returns the URL of the link, which is rewritten by the LinkRewriter if any.
+func (o *options) href(link Link) string {
+	href := o.defaultHref(link)
+	if o.rewriter != nil {
+		return o.rewriter(link, href)
+	}
+
+	return href
+}
+
+// defaultHref returns the URL of the link in the Trac environment.
+func (o *options) defaultHref(link Link) string {
+	target, fragment := splitFragment(link.Target)
+	switch link.Kind {
+	case LinkURL:
+		return link.Target
+	case LinkWiki:
+		return o.baseURL + "/wiki/" + escapePath(target) + fragment
+	case LinkTicket:
+		return o.baseURL + "/ticket/" + url.PathEscape(target) + fragment
+	case LinkChangeset:
+		return o.baseURL + "/changeset/" + url.PathEscape(target) + fragment
+	case LinkReport:
+		return o.baseURL + "/report/" + url.PathEscape(target) + fragment
+	case LinkMilestone:
+		return o.baseURL + "/milestone/" + url.PathEscape(target) + fragment
+	case LinkSource:
+		query := ""
+		if i := strings.LastIndex(target, "@"); i >= 0 {
+			target, query = target[:i], "?rev="+url.QueryEscape(target[i+1:])
+		}
+		return o.baseURL + "/browser/" + escapePath(strings.TrimPrefix(target, "/")) + query + fragment
+	case LinkAttachment:
+		parent := link.Parent
+		if parent == "" {
+			if o.page == "" {
+				return escapePath(target)
+			}
+			parent = "wiki:" + o.page
+		}
+		realm := "attachment"
+		if link.Image {
+			realm = "raw-attachment"
+		}
+		return o.baseURL + "/" + realm + "/" + escapePath(strings.Replace(parent, ":", "/", 1)+"/"+target) + fragment
+	}
+
+	return ""
+}
+
+// splitFragment splits the target into the resource and the fragment or the query.
+func splitFragment(target string) (string, string) {
+	if i := strings.IndexAny(target, "#?"); i >= 0 {
+		return target[:i], target[i:]
+	}
+
+	return target, ""
+}
+
+// escapePath escapes each segment of the path.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
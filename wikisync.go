@@ -0,0 +1,596 @@
+package tracrpc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// wikisync_page_ext is the extension of the files of the page text.
+	wikisync_page_ext string = ".wiki"
+	// wikisync_meta_ext is the extension of the sidecar files of the page metadata.
+	wikisync_meta_ext string = ".meta.json"
+	// wikisync_attachments_ext is the suffix of the directories of the attachments.
+	wikisync_attachments_ext string = ".attachments"
+	// wikisync_state_file is the file of the state of the mirror.
+	wikisync_state_file string = ".wikisync.json"
+)
+
+// WikiSync represents a mirror of the wiki in a local directory.
+//
+// The page "Dev/Setup" is stored as follows:
+//
+//	Dev/Setup.wiki               the text of the page
+//	Dev/Setup.meta.json          the metadata of the page
+//	Dev/Setup.attachments/FILE   the attachments of the page
+//
+// Deleting the local files does not delete the pages on the server.
+type WikiSync struct {
+	wiki *WikiService
+	dir  string
+}
+
+// WikiSyncResult represents the result of WikiSync.Pull and WikiSync.Push.
+type WikiSyncResult struct {
+	// Updated is the pages written locally by Pull, or on the server by Push.
+	Updated []string
+	// Deleted is the pages removed locally because they were deleted on the server.
+	Deleted []string
+	// Conflicts is the pages skipped because they were changed both locally and on the server.
+	Conflicts []string
+}
+
+// wikiSyncMeta represents the metadata of a mirrored page.
+type wikiSyncMeta struct {
+	Name         string            `json:"name"`
+	Version      int               `json:"version"`
+	LastModified time.Time         `json:"lastModified"`
+	Author       string            `json:"author"`
+	Comment      string            `json:"comment"`
+	Checksum     string            `json:"checksum"`
+	Attachments  map[string]string `json:"attachments,omitempty"`
+}
+
+// wikiSyncState represents the state of the mirror.
+type wikiSyncState struct {
+	LastModified time.Time `json:"lastModified"`
+	// Conflicts is the pages left as conflicts by the last pull, which are tried again on every pull
+	// since their changes on the server are older than LastModified.
+	Conflicts []string `json:"conflicts,omitempty"`
+}
+
+// NewWikiSync creates new WikiSync instance which mirrors the wiki into dir.
+func NewWikiSync(wiki *WikiService, dir string) (*WikiSync, error) {
+	if wiki == nil {
+		return nil, errors.New("wiki service cannot be nil")
+	}
+	if dir == "" {
+		return nil, errors.New("dir cannot be empty")
+	}
+
+	return &WikiSync{
+		wiki: wiki,
+		dir:  dir,
+	}, nil
+}
+
+// Pull writes the pages changed on the server since the last pull into the directory.
+// The first pull mirrors all pages, as PullAll does. The conflicts of the last pull are tried again.
+// NOTE: The attachments are refreshed only when their page has a new version, since
+// wiki.getRecentChanges does not report changes of attachments. Use PullAll to refresh them all.
+func (s *WikiSync) Pull() (WikiSyncResult, error) {
+	state, err := s.readState()
+	if err != nil {
+		return WikiSyncResult{}, err
+	}
+	if state.LastModified.IsZero() {
+		return s.PullAll()
+	}
+
+	changes, err := s.wiki.GetRecentChanges(&state.LastModified)
+	if err != nil {
+		return WikiSyncResult{}, err
+	}
+
+	result := WikiSyncResult{}
+	changed := make(map[string]bool, len(changes))
+	for _, info := range changes {
+		changed[info.Name] = true
+		meta, err := s.readMeta(info.Name)
+		if err != nil {
+			return result, err
+		}
+		if meta != nil && meta.Version == info.Version {
+			continue
+		}
+		if err := s.pullPage(info, meta, &result); err != nil {
+			return result, err
+		}
+		if info.LastModified.After(state.LastModified) {
+			state.LastModified = info.LastModified
+		}
+	}
+	for _, name := range state.Conflicts {
+		if changed[name] {
+			continue
+		}
+		info, err := s.wiki.GetPageInfo(String(name), nil)
+		if errors.Is(err, ErrNotFound) {
+			// the page has been deleted on the server, which PullAll handles.
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		meta, err := s.readMeta(name)
+		if err != nil {
+			return result, err
+		}
+		if err := s.pullPage(info, meta, &result); err != nil {
+			return result, err
+		}
+	}
+	state.Conflicts = result.Conflicts
+
+	return result, s.writeState(state)
+}
+
+// PullAll writes all pages into the directory, and removes the pages deleted on the server.
+func (s *WikiSync) PullAll() (WikiSyncResult, error) {
+	names, err := s.wiki.GetAllPages()
+	if err != nil {
+		return WikiSyncResult{}, err
+	}
+
+	result := WikiSyncResult{}
+	state := wikiSyncState{}
+	exists := make(map[string]bool, len(names))
+	for _, name := range names {
+		exists[name] = true
+		info, err := s.wiki.GetPageInfo(String(name), nil)
+		if err != nil {
+			return result, err
+		}
+		meta, err := s.readMeta(name)
+		if err != nil {
+			return result, err
+		}
+		if err := s.pullPage(info, meta, &result); err != nil {
+			return result, err
+		}
+		if info.LastModified.After(state.LastModified) {
+			state.LastModified = info.LastModified
+		}
+	}
+	state.Conflicts = append([]string(nil), result.Conflicts...)
+
+	locals, err := s.localPages()
+	if err != nil {
+		return result, err
+	}
+	for _, name := range locals {
+		if exists[name] {
+			continue
+		}
+		meta, err := s.readMeta(name)
+		if err != nil {
+			return result, err
+		}
+		if meta == nil {
+			// the page has been created locally and not pushed yet.
+			continue
+		}
+		modified, err := s.isModified(meta)
+		if err != nil {
+			return result, err
+		}
+		if modified {
+			result.Conflicts = append(result.Conflicts, name)
+			continue
+		}
+		if err := s.removePage(name); err != nil {
+			return result, err
+		}
+		result.Deleted = append(result.Deleted, name)
+	}
+
+	return result, s.writeState(state)
+}
+
+// Push writes the pages and the attachments changed in the directory to the server.
+// The page is skipped as a conflict if it has been changed on the server since the last pull.
+func (s *WikiSync) Push(comment string) (WikiSyncResult, error) {
+	names, err := s.localPages()
+	if err != nil {
+		return WikiSyncResult{}, err
+	}
+
+	result := WikiSyncResult{}
+	for _, name := range names {
+		meta, err := s.readMeta(name)
+		if err != nil {
+			return result, err
+		}
+		if err := s.pushPage(name, meta, comment, &result); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// pullPage writes the page and its attachments, unless they have been changed locally.
+func (s *WikiSync) pullPage(info PageInfo, meta *wikiSyncMeta, result *WikiSyncResult) error {
+	if meta != nil {
+		modified, err := s.isModified(meta)
+		if err != nil {
+			return err
+		}
+		if modified {
+			if meta.Version != info.Version {
+				result.Conflicts = append(result.Conflicts, info.Name)
+			}
+			// otherwise the local changes are waiting to be pushed.
+			return nil
+		}
+	}
+
+	pagePath, err := s.pagePath(info.Name)
+	if err != nil {
+		return err
+	}
+	if meta == nil {
+		if _, err := os.Stat(pagePath + wikisync_page_ext); err == nil {
+			// the page has been created both locally and on the server.
+			result.Conflicts = append(result.Conflicts, info.Name)
+			return nil
+		}
+	}
+	text, err := s.wiki.GetPage(String(info.Name), Int(info.Version))
+	if err != nil {
+		return err
+	}
+	if err := writeFile(pagePath+wikisync_page_ext, []byte(text)); err != nil {
+		return err
+	}
+
+	paths, err := s.wiki.ListAttachments(String(info.Name))
+	if err != nil {
+		return err
+	}
+	attachments := make(map[string]string, len(paths))
+	for _, p := range paths {
+		data, err := s.wiki.GetAttachment(String(p))
+		if err != nil {
+			return err
+		}
+		filename := path.Base(p)
+		if err := writeFile(filepath.Join(pagePath+wikisync_attachments_ext, filename), data); err != nil {
+			return err
+		}
+		attachments[filename] = checksum(data)
+	}
+	if meta != nil {
+		for filename := range meta.Attachments {
+			if _, ok := attachments[filename]; ok {
+				continue
+			}
+			if err := os.Remove(filepath.Join(pagePath+wikisync_attachments_ext, filename)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+
+	if meta == nil || meta.Version != info.Version || !equalChecksums(meta.Attachments, attachments) {
+		result.Updated = append(result.Updated, info.Name)
+	}
+
+	return s.writeMeta(&wikiSyncMeta{
+		Name:         info.Name,
+		Version:      info.Version,
+		LastModified: info.LastModified,
+		Author:       info.Author,
+		Comment:      info.Comment,
+		Checksum:     checksum([]byte(text)),
+		Attachments:  attachments,
+	})
+}
+
+// pushPage writes the page and its attachments to the server if they have been changed locally.
+func (s *WikiSync) pushPage(name string, meta *wikiSyncMeta, comment string, result *WikiSyncResult) error {
+	pagePath, err := s.pagePath(name)
+	if err != nil {
+		return err
+	}
+	text, err := ioutil.ReadFile(pagePath + wikisync_page_ext)
+	if err != nil {
+		return err
+	}
+	attachments, err := s.localAttachments(name)
+	if err != nil {
+		return err
+	}
+
+	if meta == nil {
+		meta = &wikiSyncMeta{Name: name}
+	}
+	textChanged := checksum(text) != meta.Checksum
+	changedAttachments := []string{}
+	for filename, sum := range attachments {
+		if sum != meta.Attachments[filename] {
+			changedAttachments = append(changedAttachments, filename)
+		}
+	}
+	if !textChanged && len(changedAttachments) == 0 {
+		return nil
+	}
+	sort.Strings(changedAttachments)
+
+	info, err := s.wiki.GetPageInfo(String(name), nil)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if info.Version != meta.Version {
+		result.Conflicts = append(result.Conflicts, name)
+		return nil
+	}
+
+	if textChanged {
+		attributes := PutPageAttributes{}
+		if comment != "" {
+			attributes.Comment = String(comment)
+		}
+		ok, err := s.wiki.PutPage(String(name), String(string(text)), attributes)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("page %s was not saved", name)
+		}
+	}
+	for _, filename := range changedAttachments {
+		data, err := ioutil.ReadFile(filepath.Join(pagePath+wikisync_attachments_ext, filename))
+		if err != nil {
+			return err
+		}
+		if _, err := s.wiki.PutAttachmentEx(String(name), String(filename), String(comment), data, Bool(true)); err != nil {
+			return err
+		}
+	}
+
+	info, err = s.wiki.GetPageInfo(String(name), nil)
+	if err != nil {
+		return err
+	}
+	result.Updated = append(result.Updated, name)
+
+	return s.writeMeta(&wikiSyncMeta{
+		Name:         name,
+		Version:      info.Version,
+		LastModified: info.LastModified,
+		Author:       info.Author,
+		Comment:      info.Comment,
+		Checksum:     checksum(text),
+		Attachments:  attachments,
+	})
+}
+
+// isModified reports whether the page or its attachments have been changed locally since the last sync.
+func (s *WikiSync) isModified(meta *wikiSyncMeta) (bool, error) {
+	pagePath, err := s.pagePath(meta.Name)
+	if err != nil {
+		return false, err
+	}
+	text, err := ioutil.ReadFile(pagePath + wikisync_page_ext)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if checksum(text) != meta.Checksum {
+		return true, nil
+	}
+
+	attachments, err := s.localAttachments(meta.Name)
+	if err != nil {
+		return false, err
+	}
+	for filename, sum := range attachments {
+		if sum != meta.Attachments[filename] {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// removePage removes the files of the page.
+func (s *WikiSync) removePage(name string) error {
+	pagePath, err := s.pagePath(name)
+	if err != nil {
+		return err
+	}
+	for _, p := range []string{pagePath + wikisync_page_ext, pagePath + wikisync_meta_ext} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return os.RemoveAll(pagePath + wikisync_attachments_ext)
+}
+
+// localPages returns the names of the pages in the directory.
+func (s *WikiSync) localPages() ([]string, error) {
+	names := []string{}
+	err := filepath.Walk(s.dir, func(p string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) && p == s.dir {
+			return filepath.SkipDir
+		}
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if strings.HasSuffix(info.Name(), wikisync_attachments_ext) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, wikisync_page_ext) {
+			return nil
+		}
+		rel, err := filepath.Rel(s.dir, p)
+		if err != nil {
+			return err
+		}
+		names = append(names, strings.TrimSuffix(filepath.ToSlash(rel), wikisync_page_ext))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// localAttachments returns the checksums of the attachments of the page in the directory.
+func (s *WikiSync) localAttachments(name string) (map[string]string, error) {
+	pagePath, err := s.pagePath(name)
+	if err != nil {
+		return nil, err
+	}
+	files, err := ioutil.ReadDir(pagePath + wikisync_attachments_ext)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := make(map[string]string, len(files))
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(pagePath+wikisync_attachments_ext, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		attachments[file.Name()] = checksum(data)
+	}
+
+	return attachments, nil
+}
+
+// pagePath returns the path of the page files without the extension.
+func (s *WikiSync) pagePath(name string) (string, error) {
+	for _, segment := range strings.Split(name, "/") {
+		if segment == "" || segment == "." || segment == ".." || strings.ContainsAny(segment, `\:`) {
+			return "", fmt.Errorf("page name %s cannot be mapped to a file", name)
+		}
+	}
+
+	return filepath.Join(s.dir, filepath.FromSlash(name)), nil
+}
+
+// readMeta reads the metadata of the page. It returns nil if the page has not been synced yet.
+func (s *WikiSync) readMeta(name string) (*wikiSyncMeta, error) {
+	pagePath, err := s.pagePath(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(pagePath + wikisync_meta_ext)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &wikiSyncMeta{}
+	if err := json.Unmarshal(data, meta); err != nil {
+		return nil, fmt.Errorf("invalid metadata of page %s: %w", name, err)
+	}
+
+	return meta, nil
+}
+
+// writeMeta writes the metadata of the page.
+func (s *WikiSync) writeMeta(meta *wikiSyncMeta) error {
+	pagePath, err := s.pagePath(meta.Name)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFile(pagePath+wikisync_meta_ext, append(data, '\n'))
+}
+
+// readState reads the state of the mirror. It returns the zero state if the wiki has not been pulled yet.
+func (s *WikiSync) readState() (wikiSyncState, error) {
+	state := wikiSyncState{}
+	data, err := ioutil.ReadFile(filepath.Join(s.dir, wikisync_state_file))
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("invalid state of the mirror: %w", err)
+	}
+
+	return state, nil
+}
+
+// writeState writes the state of the mirror.
+func (s *WikiSync) writeState(state wikiSyncState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFile(filepath.Join(s.dir, wikisync_state_file), append(data, '\n'))
+}
+
+// writeFile writes the file, creating the parent directories.
+func writeFile(name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(name, data, 0644)
+}
+
+// equalChecksums reports whether the checksums of the files are the same.
+func equalChecksums(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for filename, sum := range a {
+		if b[filename] != sum {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checksum returns the SHA-256 checksum of data in hex.
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,220 @@
+package tracrpc
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/f-velka/tracrpc/internal/xmlrpcwire"
+	"github.com/f-velka/tracrpc/tractest"
+)
+
+func TestWikiSync(t *testing.T) {
+	srv := tractest.NewServer()
+	defer srv.Close()
+	now := time.Date(2021, 3, 3, 9, 0, 0, 0, time.UTC)
+	srv.Now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	srv.AddPage("WikiStart", "= Welcome =", "trac", "")
+	srv.AddPage("Dev/Setup", "install go", "ii", "")
+	srv.AddWikiAttachment("Dev/Setup", "setup.sh", "", "ii", []byte("go build"))
+
+	client, err := NewClient(srv.URL+"/login/rpc", nil, WithBasicAuth("naosuke", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	sync, err := NewWikiSync(client.Wiki, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectFile := func(name, expected string) {
+		t.Helper()
+		data, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != expected {
+			t.Fatalf("unexpected %s. expected=%q, got=%q", name, expected, string(data))
+		}
+	}
+	expectPage := func(name, expected string) {
+		t.Helper()
+		text, _ := srv.Page(name)
+		if text != expected {
+			t.Fatalf("unexpected page %s. expected=%q, got=%q", name, expected, text)
+		}
+	}
+	expectResult := func(result WikiSyncResult, err error, expected WikiSyncResult) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("unexpected result. expected=%+v, got=%+v", expected, result)
+		}
+	}
+
+	// the first pull mirrors all pages.
+	result, err := sync.Pull()
+	expectResult(result, err, WikiSyncResult{Updated: []string{"Dev/Setup", "WikiStart"}})
+	expectFile("WikiStart.wiki", "= Welcome =")
+	expectFile("Dev/Setup.wiki", "install go")
+	expectFile("Dev/Setup.attachments/setup.sh", "go build")
+
+	// local edits are pushed.
+	if err := ioutil.WriteFile(filepath.Join(dir, "Dev", "Setup.wiki"), []byte("install go 1.16"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "Dev", "Setup.attachments", "setup.sh"), []byte("go install"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "Dev", "Test"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "Dev", "Test.wiki"), []byte("go test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err = sync.Push("sync")
+	expectResult(result, err, WikiSyncResult{Updated: []string{"Dev/Setup", "Dev/Test"}})
+	expectPage("Dev/Setup", "install go 1.16")
+	expectPage("Dev/Test", "go test")
+	data, err := client.Wiki.GetAttachment(String("Dev/Setup/setup.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "go install" {
+		t.Fatalf("unexpected attachment. expected=%v, got=%v", "go install", string(data))
+	}
+
+	// nothing is pushed twice.
+	result, err = sync.Push("sync")
+	expectResult(result, err, WikiSyncResult{})
+
+	// incremental pull writes the pages changed on the server only.
+	srv.AddPage("WikiStart", "= Welcome to Trac =", "trac", "")
+	result, err = sync.Pull()
+	expectResult(result, err, WikiSyncResult{Updated: []string{"WikiStart"}})
+	expectFile("WikiStart.wiki", "= Welcome to Trac =")
+
+	// the page changed on both sides is a conflict.
+	srv.AddPage("Dev/Test", "go test ./...", "ii", "")
+	if err := ioutil.WriteFile(filepath.Join(dir, "Dev", "Test.wiki"), []byte("go test -race"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err = sync.Push("sync")
+	expectResult(result, err, WikiSyncResult{Conflicts: []string{"Dev/Test"}})
+	expectPage("Dev/Test", "go test ./...")
+	result, err = sync.Pull()
+	expectResult(result, err, WikiSyncResult{Conflicts: []string{"Dev/Test"}})
+	expectFile("Dev/Test.wiki", "go test -race")
+
+	// the conflict is pulled again once the local changes are discarded.
+	result, err = sync.Pull()
+	expectResult(result, err, WikiSyncResult{Conflicts: []string{"Dev/Test"}})
+	if err := ioutil.WriteFile(filepath.Join(dir, "Dev", "Test.wiki"), []byte("go test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err = sync.Pull()
+	expectResult(result, err, WikiSyncResult{Updated: []string{"Dev/Test"}})
+	expectFile("Dev/Test.wiki", "go test ./...")
+	result, err = sync.Pull()
+	expectResult(result, err, WikiSyncResult{})
+
+	// the pages deleted on the server are removed by PullAll.
+	if _, err := client.Wiki.DeletePage(String("Dev/Setup"), nil); err != nil {
+		t.Fatal(err)
+	}
+	result, err = sync.PullAll()
+	expectResult(result, err, WikiSyncResult{Deleted: []string{"Dev/Setup"}})
+	if _, err := os.Stat(filepath.Join(dir, "Dev", "Setup.attachments")); !os.IsNotExist(err) {
+		t.Fatalf("unexpected attachments. expected to be removed, got=%v", err)
+	}
+}
+
+func TestWikiSyncPushFault(t *testing.T) {
+	srv := tractest.NewServer()
+	defer srv.Close()
+	srv.AddPage("WikiStart", "= Welcome =", "trac", "")
+
+	// the proxy denies wiki.putPage as Trac does for the users without WIKI_MODIFY.
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if bytes.Contains(body, []byte("<methodName>wiki.putPage</methodName>")) {
+			w.Write(xmlrpcwire.EncodeFault(tractest.FaultPermissionDenied, "WIKI_MODIFY privileges are required to perform this operation on WikiStart"))
+			return
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		srv.ServeHTTP(w, req)
+	}))
+	defer proxy.Close()
+
+	client, err := NewClient(proxy.URL+"/rpc", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	sync, err := NewWikiSync(client.Wiki, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sync.Pull(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "WikiStart.wiki"), []byte("= Welcome to Trac ="), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = sync.Push("sync")
+	if !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("unexpected error. expected=%v, got=%v", ErrPermissionDenied, err)
+	}
+	if text, _ := srv.Page("WikiStart"); text != "= Welcome =" {
+		t.Fatalf("unexpected page. expected=%q, got=%q", "= Welcome =", text)
+	}
+}
+
+func TestWikiSyncPagePath(t *testing.T) {
+	sync, err := NewWikiSync(&WikiService{}, "mirror")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		expected      string
+		expectedError bool
+	}{
+		{"WikiStart", filepath.Join("mirror", "WikiStart"), false},
+		{"Dev/Setup", filepath.Join("mirror", "Dev", "Setup"), false},
+		{"Dev/../../etc", "", true},
+		{"/Dev", "", true},
+		{`Dev\Setup`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sync.pagePath(tt.name)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("unexpected error. expected=%v, got=%v", tt.expectedError, err)
+			}
+			if got != tt.expected {
+				t.Fatalf("unexpected result. expected=%v, got=%v", tt.expected, got)
+			}
+		})
+	}
+}
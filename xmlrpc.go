@@ -0,0 +1,79 @@
+package tracrpc
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/kolo/xmlrpc"
+)
+
+// xmlrpcClient represents RpcClient over XML-RPC.
+type xmlrpcClient struct {
+	url        string
+	httpClient *http.Client
+}
+
+// newXmlrpcClient creates new xmlrpcClient instance.
+// Cookies received from the server are sent back in the subsequent calls.
+func newXmlrpcClient(rawurl string, transport http.RoundTripper) (*xmlrpcClient, error) {
+	if _, err := url.Parse(rawurl); err != nil {
+		return nil, err
+	}
+	httpClient, err := newHTTPClient(transport)
+	if err != nil {
+		return nil, err
+	}
+
+	return &xmlrpcClient{
+		url:        rawurl,
+		httpClient: httpClient,
+	}, nil
+}
+
+// Call calls the method without deadline.
+func (c *xmlrpcClient) Call(methodName string, args interface{}, reply interface{}) error {
+	return c.CallContext(context.Background(), methodName, args, reply)
+}
+
+// CallContext calls the method. The request is canceled when ctx is done.
+func (c *xmlrpcClient) CallContext(ctx context.Context, methodName string, args interface{}, reply interface{}) error {
+	req, err := xmlrpc.NewRequest(c.url, methodName, args)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	xmlResp := xmlrpc.Response(body)
+	if err := xmlResp.Err(); err != nil {
+		if fault, ok := err.(xmlrpc.FaultError); ok {
+			return newFault(methodName, fault.Code, fault.String)
+		}
+		return err
+	}
+	if reply == nil {
+		return nil
+	}
+
+	return xmlResp.Unmarshal(reply)
+}
@@ -0,0 +1,140 @@
+package tracrpc
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestXmlrpcClientCallContext(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		reply    string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:   "OK",
+			status: http.StatusOK,
+			reply: `<?xml version='1.0'?>
+<methodResponse>
+<params>
+<param>
+<value><string>Shiga</string></value>
+</param>
+</params>
+</methodResponse>`,
+			expected: "Shiga",
+		},
+		{
+			name:    "BadStatus",
+			status:  http.StatusServiceUnavailable,
+			reply:   "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				if !strings.Contains(string(body), "<methodName>wiki.getPage</methodName>") {
+					t.Errorf("unexpected request. got=%s", body)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.reply))
+			}))
+			defer server.Close()
+
+			c, err := newXmlrpcClient(server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var res string
+			err = c.CallContext(context.Background(), wiki_get_page, packArgs(String("shiga")), &res)
+			if err != nil {
+				if tt.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				t.Fatal("expected error, got nil")
+			}
+			if res != tt.expected {
+				t.Fatalf("unexpected result. expected=%v, got=%v", tt.expected, res)
+			}
+		})
+	}
+}
+
+func TestXmlrpcClientFault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version='1.0'?>
+<methodResponse>
+<fault>
+<value><struct>
+<member>
+<name>faultCode</name>
+<value><int>403</int></value>
+</member>
+<member>
+<name>faultString</name>
+<value><string>WIKI_VIEW privileges are required</string></value>
+</member>
+</struct></value>
+</fault>
+</methodResponse>`))
+	}))
+	defer server.Close()
+
+	c, err := newXmlrpcClient(server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res string
+	err = c.Call(wiki_get_page, packArgs(String("shiga")), &res)
+	expected := &Fault{Code: 403, Message: "WIKI_VIEW privileges are required", Method: wiki_get_page}
+	if !reflect.DeepEqual(err, expected) {
+		t.Fatalf("unexpected error. expected=%v, got=%v", expected, err)
+	}
+}
+
+func TestClientWithContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	c, err := NewClient(server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = c.WithContext(ctx).Wiki.GetPage(String("shiga"), nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error. expected=%v, got=%v", context.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("the call was not canceled promptly. elapsed=%v", elapsed)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.WithContext(canceled).Ticket.GetMany([]int{1, 2})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error. expected=%v, got=%v", context.Canceled, err)
+	}
+}